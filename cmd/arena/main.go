@@ -0,0 +1,256 @@
+// Command arena plays configurations of the engine against each other and
+// reports how strong they are compared to each other, to check that the
+// deeper searches and better evaluations really play better:
+//
+//	arena 1 2 3 4
+//	arena -openings 200 4 4:positional 4:greedy 4:nobook
+//
+// A player is a depth, followed by an evaluation of the engine or "nobook"
+// after colons. Every player plays every other one from the same random
+// openings, once with each color, so neither is favored by the openings.
+// The later players are expected to beat the earlier ones, and the exit
+// status is 1 if one of them is clearly weaker than an earlier one.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ArminGh02/othello-bot/pkg/engine"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+)
+
+var (
+	openings    = flag.Int("openings", 50, "the number of openings each pair of players plays, with both colors")
+	randomMoves = flag.Int("random-moves", 4, "the number of random moves of the openings")
+	workers     = flag.Int("workers", runtime.NumCPU(), "the number of games played at the same time")
+	seed        = flag.Int64("seed", 1, "the seed of the random openings")
+)
+
+// player is a configuration of the engine.
+type player struct {
+	name string
+	opts engine.Options
+}
+
+// match is a game of the players of a pair, where first plays black if
+// firstBlack.
+type match struct {
+	pair       int
+	first      player
+	second     player
+	opening    *othellogame.Position
+	firstBlack bool
+}
+
+// result is the score of the first player of a match.
+type result struct {
+	pair  int
+	score float64
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: arena [flags] player player...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	specs := flag.Args()
+	if len(specs) == 0 {
+		specs = []string{"1", "2", "3", "4"}
+	}
+	if *workers < 1 {
+		log.Fatalln("At least one worker is needed.")
+	}
+	if len(specs) < 2 {
+		log.Fatalln("At least two players are needed.")
+	}
+	players := make([]player, len(specs))
+	for i, spec := range specs {
+		p, err := parsePlayer(spec)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		players[i] = p
+	}
+
+	positions := randomOpenings(*openings, *randomMoves, rand.New(rand.NewSource(*seed)))
+	if len(positions) < *openings {
+		log.Printf("Found only %d different openings of %d moves.", len(positions), *randomMoves)
+	}
+
+	// pairs are the indexes of the players of each pair, the later one first
+	pairs := make([][2]int, 0)
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			pairs = append(pairs, [2]int{j, i})
+		}
+	}
+	log.Printf("Playing %d games of %d players on %d workers...",
+		2*len(pairs)*len(positions), len(players), *workers)
+
+	tallies := play(players, pairs, positions)
+	if !report(os.Stdout, players, pairs, tallies) {
+		os.Exit(1)
+	}
+}
+
+// parsePlayer parses players like "4" or "4:positional:nobook".
+func parsePlayer(spec string) (player, error) {
+	fields := strings.Split(spec, ":")
+	depth, err := strconv.Atoi(fields[0])
+	if err != nil || depth < 1 {
+		return player{}, fmt.Errorf("invalid depth of player %q", spec)
+	}
+	p := player{name: spec, opts: engine.Options{Depth: depth}}
+	for _, field := range fields[1:] {
+		if field == "nobook" {
+			p.opts.NoBook = true
+			continue
+		}
+		e, ok := engine.ParseEvaluation(field)
+		if !ok {
+			return player{}, fmt.Errorf("invalid option %q of player %q, which is either \"nobook\" or one of %v",
+				field, spec, engine.Evaluations)
+		}
+		p.opts.Evaluation = e
+	}
+	return p, nil
+}
+
+// randomOpenings returns up to n different positions reached by playing
+// moves random moves from the standard position.
+func randomOpenings(n, moves int, rnd *rand.Rand) []*othellogame.Position {
+	res := make([]*othellogame.Position, 0, n)
+	seen := make(map[uint64]bool)
+	for tries := 0; len(res) < n && tries < 100*n; tries++ {
+		game := othellogame.NewWithOptions(nil, nil, othellogame.Options{
+			Start: othellogame.StandardPosition(othellogame.DefaultBoardSize, false),
+		})
+		for i := 0; i < moves && !game.IsEnded(); i++ {
+			legalMoves := game.LegalMoves()
+			game.PlaceDiskUnchecked(legalMoves[rnd.Intn(len(legalMoves))])
+		}
+		if game.IsEnded() || seen[game.CanonicalHash()] {
+			continue
+		}
+		seen[game.CanonicalHash()] = true
+		res = append(res, game.Position())
+	}
+	return res
+}
+
+// play plays the matches of the pairs from each opening on the workers, and
+// returns the results of the first players of the pairs.
+func play(players []player, pairs [][2]int, positions []*othellogame.Position) []tally {
+	matches := make(chan match)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range matches {
+				results <- result{pair: m.pair, score: playMatch(m)}
+			}
+		}()
+	}
+
+	go func() {
+		for i, pair := range pairs {
+			for _, opening := range positions {
+				for _, firstBlack := range [...]bool{true, false} {
+					matches <- match{
+						pair:       i,
+						first:      players[pair[0]],
+						second:     players[pair[1]],
+						opening:    opening,
+						firstBlack: firstBlack,
+					}
+				}
+			}
+		}
+		close(matches)
+		wg.Wait()
+		close(results)
+	}()
+
+	tallies := make([]tally, len(pairs))
+	for r := range results {
+		tallies[r.pair].add(r.score)
+	}
+	return tallies
+}
+
+// playMatch plays the game of the match to the end, and returns the score
+// of its first player.
+func playMatch(m match) float64 {
+	game := othellogame.NewWithOptions(nil, nil, othellogame.Options{Start: m.opening})
+	for !game.IsEnded() {
+		p := m.second
+		if game.WhiteToMove() != m.firstBlack {
+			p = m.first
+		}
+		move, _ := engine.BestMoveWithOptions(game, p.opts)
+		game.PlaceDiskUnchecked(move)
+	}
+
+	first, second := game.BlackDisks(), game.WhiteDisks()
+	if !m.firstBlack {
+		first, second = second, first
+	}
+	switch {
+	case first > second:
+		return 1
+	case first < second:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// report writes the results of the pairs, and returns false if a player is
+// clearly weaker than an earlier one.
+func report(w io.Writer, players []player, pairs [][2]int, tallies []tally) bool {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Player\tOpponent\tGames\tWins\tDraws\tLosses\tScore\t95% CI\tElo\t95% CI\t")
+	for i, pair := range pairs {
+		t := tallies[i]
+		lo, hi := t.interval()
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%.1f%%\t%.1f%%–%.1f%%\t%s\t%s–%s\t\n",
+			players[pair[0]].name, players[pair[1]].name,
+			t.games(), t.wins, t.draws, t.losses,
+			100*t.score(), 100*lo, 100*hi,
+			formatElo(elo(t.score())), formatElo(elo(lo)), formatElo(elo(hi)))
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	monotonic := true
+	for i, pair := range pairs {
+		lo, hi := tallies[i].interval()
+		later, earlier := players[pair[0]].name, players[pair[1]].name
+		switch {
+		case hi < 0.5:
+			monotonic = false
+			fmt.Fprintf(w, "%s is weaker than %s.\n", later, earlier)
+		case lo <= 0.5:
+			fmt.Fprintf(w, "%s isn't clearly stronger than %s; more openings may tell.\n", later, earlier)
+		}
+	}
+	if monotonic {
+		fmt.Fprintln(w, "No player is weaker than the ones before it.")
+	}
+	return monotonic
+}
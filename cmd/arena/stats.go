@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// z is the quantile of the normal distribution of 95% confidence intervals.
+const z = 1.96
+
+// tally is the results of the games of a player against another.
+type tally struct {
+	wins, draws, losses int
+}
+
+func (t *tally) add(score float64) {
+	switch score {
+	case 1:
+		t.wins++
+	case 0:
+		t.losses++
+	default:
+		t.draws++
+	}
+}
+
+func (t tally) games() int {
+	return t.wins + t.draws + t.losses
+}
+
+// score returns the points of the player per game, counting draws as half
+// a point.
+func (t tally) score() float64 {
+	if t.games() == 0 {
+		return 0.5
+	}
+	return (float64(t.wins) + float64(t.draws)/2) / float64(t.games())
+}
+
+// interval returns the 95% confidence interval of score, from the variance
+// of the points of the games.
+func (t tally) interval() (lo, hi float64) {
+	n := float64(t.games())
+	if n == 0 {
+		return 0, 1
+	}
+	s := t.score()
+	variance := (float64(t.wins)*(1-s)*(1-s) +
+		float64(t.draws)*(0.5-s)*(0.5-s) +
+		float64(t.losses)*s*s) / n
+	margin := z * math.Sqrt(variance/n)
+	return math.Max(0, s-margin), math.Min(1, s+margin)
+}
+
+// elo returns the difference of Elo ratings at which the stronger player
+// is expected to score as much, which is infinite for scores of 0 and 1.
+func elo(score float64) float64 {
+	switch {
+	case score <= 0:
+		return math.Inf(-1)
+	case score >= 1:
+		return math.Inf(1)
+	}
+	return -400 * math.Log10(1/score-1)
+}
+
+func formatElo(e float64) string {
+	if math.IsInf(e, 0) {
+		if e > 0 {
+			return "+inf"
+		}
+		return "-inf"
+	}
+	return fmt.Sprintf("%+.0f", e)
+}
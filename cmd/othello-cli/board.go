@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+const (
+	ansiReset      = "\x1b[0m"
+	ansiBoard      = "\x1b[42m"
+	ansiBlackDisk  = "\x1b[30;42m"
+	ansiWhiteDisk  = "\x1b[97;42m"
+	ansiLegalMove  = "\x1b[33;42m"
+	ansiHint       = "\x1b[1;93;42m"
+	ansiLastMove   = "\x1b[4m"
+	ansiHole       = "\x1b[90;40m"
+	ansiCoordinate = "\x1b[2m"
+)
+
+// symbols are the ones cells are drawn with, with and without colors.
+var (
+	colorSymbols = map[cell.Cell]string{
+		cell.Empty: " ",
+		cell.Black: "●",
+		cell.White: "●",
+		cell.Hole:  "█",
+	}
+	plainSymbols = map[cell.Cell]string{
+		cell.Empty: ".",
+		cell.Black: "X",
+		cell.White: "O",
+		cell.Hole:  "#",
+	}
+)
+
+// printBoard draws the board of game with the legal moves and the hint,
+// if there is one, marked.
+func printBoard(w io.Writer, game *othellogame.Game, hint *coord.Coord, color bool) {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+	symbols := plainSymbols
+	if color {
+		symbols = colorSymbols
+	}
+	lastMove, moved := game.LastMove()
+
+	var b strings.Builder
+	b.WriteString("    ")
+	for x := 0; x < game.Size(); x++ {
+		b.WriteString(paint(ansiCoordinate, fmt.Sprintf("%c ", 'a'+x)))
+	}
+	b.WriteByte('\n')
+	for y, row := range game.Board() {
+		b.WriteString(paint(ansiCoordinate, fmt.Sprintf("%3d ", y+1)))
+		for x, c := range row {
+			where := coord.New(x, y)
+			symbol, code := symbols[c], ansiBoard
+			switch {
+			case hint != nil && *hint == where:
+				symbol, code = "*", ansiHint
+				if !color {
+					symbol = "!"
+				}
+			case c == cell.Empty && game.IsLegalMove(where):
+				symbol, code = "·", ansiLegalMove
+				if !color {
+					symbol = "*"
+				}
+			case c == cell.Black:
+				code = ansiBlackDisk
+			case c == cell.White:
+				code = ansiWhiteDisk
+			case c == cell.Hole:
+				code = ansiHole
+			}
+			if moved && lastMove == where {
+				code += ansiLastMove
+			}
+			b.WriteString(paint(code, symbol))
+			b.WriteString(paint(ansiBoard, " "))
+		}
+		b.WriteByte('\n')
+	}
+	io.WriteString(w, b.String())
+}
+
+// colorName returns the name of the color of the disks of the cell.
+func colorName(c cell.Cell) string {
+	if c == cell.White {
+		return "White"
+	}
+	return "Black"
+}
@@ -0,0 +1,233 @@
+// Command othello-cli plays othello in the terminal, between two people at
+// the same keyboard, a person and the engine, or the engine and itself, to
+// try the rules and the engine without Telegram or a database:
+//
+//	othello-cli -white ai -depth 6
+//	othello-cli -load game.txt
+//
+// Type "help" at the prompt for the commands.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ArminGh02/othello-bot/pkg/engine"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+var (
+	black   = flag.String("black", "human", `who plays black: "human" or "ai"`)
+	white   = flag.String("white", "human", `who plays white: "human" or "ai"`)
+	depth   = flag.Int("depth", 4, "how many moves ahead the engine searches")
+	size    = flag.Int("size", othellogame.DefaultBoardSize, "the size of the board")
+	anti    = flag.Bool("anti", false, "play anti-othello, where the player with fewer disks wins")
+	load    = flag.String("load", "", "the record of a game to continue")
+	noColor = flag.Bool("no-color", false, "draw the board without colors")
+)
+
+const helpText = `Commands:
+  d3         place a disk on d3
+  hint       show the move the engine would play
+  undo       take back your last move
+  moves      list the legal moves
+  save FILE  save the record of the game
+  load FILE  continue the game of a record
+  new        start a new game
+  quit       quit
+`
+
+// session is a game played in the terminal.
+type session struct {
+	record *record
+	game   *othellogame.Game
+	// ai tells whether the engine plays white and black.
+	ai    map[cell.Cell]bool
+	depth int
+	color bool
+	hint  *coord.Coord
+	out   io.Writer
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	s := &session{
+		ai:    map[cell.Cell]bool{cell.Black: isAI(*black), cell.White: isAI(*white)},
+		depth: *depth,
+		color: !*noColor,
+		out:   os.Stdout,
+	}
+	if *load != "" {
+		r, err := loadRecord(*load)
+		if err != nil {
+			log.Fatalln("Error loading the record:", err)
+		}
+		s.reset(r)
+	} else {
+		if !othellogame.IsValidBoardSize(*size) {
+			log.Fatalln("Invalid board size:", *size)
+		}
+		s.reset(newRecord(*size, *anti))
+	}
+	s.run(bufio.NewScanner(os.Stdin))
+}
+
+func isAI(player string) bool {
+	switch player {
+	case "ai":
+		return true
+	case "human":
+		return false
+	default:
+		log.Fatalf("Invalid player %q, which is either \"human\" or \"ai\".", player)
+		return false
+	}
+}
+
+func newRecord(size int, anti bool) *record {
+	v := variant.Classic
+	if anti {
+		v = variant.Anti
+	}
+	return &record{variant: v, start: othellogame.StandardPosition(size, false)}
+}
+
+// reset starts playing the game of the record from its last move.
+func (s *session) reset(r *record) {
+	s.record = r
+	s.game = r.newGame()
+	for _, move := range r.moves {
+		s.game.PlaceDiskUnchecked(move)
+	}
+	s.hint = nil
+}
+
+func (s *session) run(in *bufio.Scanner) {
+	for {
+		for !s.game.IsEnded() && s.ai[s.game.ActiveCell()] {
+			s.printBoard()
+			move, _ := engine.BestMove(s.game, s.depth)
+			fmt.Fprintf(s.out, "%s plays %s.\n", colorName(s.game.ActiveCell()), move)
+			s.play(move)
+		}
+		s.printBoard()
+		if s.game.IsEnded() {
+			s.printResult()
+		}
+
+		fmt.Fprint(s.out, "> ")
+		if !in.Scan() {
+			fmt.Fprintln(s.out)
+			return
+		}
+		command, arg, _ := strings.Cut(strings.TrimSpace(in.Text()), " ")
+		arg = strings.TrimSpace(arg)
+		switch strings.ToLower(command) {
+		case "":
+		case "quit", "exit", "q":
+			return
+		case "help", "?":
+			fmt.Fprint(s.out, helpText)
+		case "hint":
+			if move, ok := engine.BestMove(s.game, s.depth); ok {
+				s.hint = &move
+				fmt.Fprintf(s.out, "Hint: %s\n", move)
+			}
+		case "undo":
+			s.undo()
+		case "moves":
+			moves := make([]string, 0)
+			for _, move := range s.game.LegalMoves() {
+				moves = append(moves, move.String())
+			}
+			fmt.Fprintln(s.out, strings.Join(moves, " "))
+		case "save":
+			if err := saveRecord(arg, s.record); err != nil {
+				fmt.Fprintln(s.out, "Error saving the record:", err)
+			} else {
+				fmt.Fprintln(s.out, "Saved to", arg)
+			}
+		case "load":
+			r, err := loadRecord(arg)
+			if err != nil {
+				fmt.Fprintln(s.out, "Error loading the record:", err)
+				break
+			}
+			s.reset(r)
+		case "new":
+			s.reset(newRecord(s.record.start.Size(), s.record.variant == variant.Anti))
+		default:
+			move, err := coord.Parse(command, s.game.Size())
+			switch {
+			case err != nil:
+				fmt.Fprintf(s.out, "Unknown command %q. Type \"help\" for the commands.\n", command)
+			case s.game.IsEnded():
+				fmt.Fprintln(s.out, "The game is over.")
+			case !s.game.IsLegalMove(move):
+				fmt.Fprintf(s.out, "%s is not a legal move.\n", move)
+			default:
+				s.play(move)
+			}
+		}
+	}
+}
+
+func (s *session) play(move coord.Coord) {
+	s.record.moves = append(s.record.moves, move)
+	s.hint = nil
+	if s.game.PlaceDiskUnchecked(move) {
+		fmt.Fprintf(s.out, "%s has no moves and passes.\n", colorName(s.game.ActiveCell().Reversed()))
+	}
+}
+
+// undo takes back the last move of a person, and the moves the engine made
+// after it.
+func (s *session) undo() {
+	moves := s.record.moves
+	if len(moves) == 0 {
+		fmt.Fprintln(s.out, "There is no move to take back.")
+		return
+	}
+	for {
+		moves = moves[:len(moves)-1]
+		s.reset(&record{variant: s.record.variant, start: s.record.start, moves: moves})
+		if len(moves) == 0 || !s.ai[s.game.ActiveCell()] {
+			return
+		}
+	}
+}
+
+func (s *session) printBoard() {
+	fmt.Fprintln(s.out)
+	printBoard(s.out, s.game, s.hint, s.color)
+	fmt.Fprintf(s.out, "Black %d : %d White", s.game.BlackDisks(), s.game.WhiteDisks())
+	if !s.game.IsEnded() {
+		fmt.Fprintf(s.out, "   %s to move", colorName(s.game.ActiveCell()))
+	}
+	fmt.Fprintln(s.out)
+}
+
+func (s *session) printResult() {
+	black, white := s.game.BlackDisks(), s.game.WhiteDisks()
+	if s.game.Variant() == variant.Anti {
+		black, white = white, black
+	}
+	switch {
+	case black > white:
+		fmt.Fprintln(s.out, "Black wins!")
+	case white > black:
+		fmt.Fprintln(s.out, "White wins!")
+	default:
+		fmt.Fprintln(s.out, "It's a draw!")
+	}
+}
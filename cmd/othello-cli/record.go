@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ArminGh02/othello-bot/pkg/gifmaker"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+// A record of a game is the transcript the bot sends as the text replay,
+// after a "Variant:" line for the games of other variants than othello:
+//
+//	Variant: anti
+//	Start: --------/--------/--------/---wb---/---bw---/--------/--------/-------- b
+//	1. d3 c5
+//	2. f6 --
+//	White 30 - 34 Black
+//
+// The transcripts the bot sends without a "Start:" line are of games from
+// the standard position with black moving first.
+
+// record is a game read from a record.
+type record struct {
+	variant variant.Variant
+	start   *othellogame.Position
+	moves   []coord.Coord
+}
+
+func saveRecord(path string, r *record) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if r.variant != variant.Classic {
+		fmt.Fprintf(f, "Variant: %s\n", r.variant)
+	}
+	opts := gifmaker.DefaultOptions()
+	opts.Format = gifmaker.FormatText
+	opts.Start = r.start
+	if err := gifmaker.Make(f, r.moves, r.start.WhiteToMove, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func loadRecord(path string) (*record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readRecord(f)
+}
+
+func readRecord(r io.Reader) (*record, error) {
+	res := &record{
+		variant: variant.Classic,
+		start:   othellogame.StandardPosition(othellogame.DefaultBoardSize, false),
+	}
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "White "):
+			// the final score
+		case strings.HasPrefix(line, "Variant:"):
+			v, ok := variant.Parse(strings.TrimSpace(strings.TrimPrefix(line, "Variant:")))
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown variant", lineNumber)
+			}
+			res.variant = v
+		case strings.HasPrefix(line, "Start:"):
+			start, err := othellogame.ParsePosition(strings.TrimSpace(strings.TrimPrefix(line, "Start:")))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			res.start = start
+		default:
+			for _, field := range strings.Fields(line) {
+				if field == "--" || strings.HasSuffix(field, ".") {
+					// passes are made by the game, and the numbers of the moves
+					continue
+				}
+				move, err := coord.Parse(field, res.start.Size())
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+				}
+				res.moves = append(res.moves, move)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	game := res.newGame()
+	for i, move := range res.moves {
+		if !game.IsLegalMove(move) {
+			return nil, fmt.Errorf("move %d, %s, is illegal", i+1, move)
+		}
+		game.PlaceDiskUnchecked(move)
+	}
+	return res, nil
+}
+
+func (r *record) newGame() *othellogame.Game {
+	return othellogame.NewWithOptions(nil, nil, othellogame.Options{
+		Start:   r.start,
+		Variant: r.variant,
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+// passPosition is a position where white passes after black takes a1.
+const passPosition = "-wb-----/--------/--------/--------/--------/--------/--------/-----wwb b"
+
+func TestRecordRoundTrip(t *testing.T) {
+	start, err := othellogame.ParsePosition(passPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &record{
+		variant: variant.Anti,
+		start:   start,
+		moves:   []coord.Coord{coord.New(0, 0), coord.New(4, 7)},
+	}
+
+	path := filepath.Join(t.TempDir(), "game.txt")
+	if err := saveRecord(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadRecord(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.variant != want.variant {
+		t.Errorf("variant is %q, want %q", got.variant, want.variant)
+	}
+	if got.start.String() != want.start.String() {
+		t.Errorf("start is %q, want %q", got.start, want.start)
+	}
+	if !reflect.DeepEqual(got.moves, want.moves) {
+		t.Errorf("moves are %v, want %v", got.moves, want.moves)
+	}
+}
+
+func TestReadRecord(t *testing.T) {
+	text := "Start: " + passPosition + "\n" +
+		"1. a1 --\n" +
+		"2. e8\n" +
+		"White 0 - 7 Black\n"
+	r, err := readRecord(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []coord.Coord{coord.New(0, 0), coord.New(4, 7)}; !reflect.DeepEqual(r.moves, want) {
+		t.Errorf("moves are %v, want %v", r.moves, want)
+	}
+	if r.variant != variant.Classic {
+		t.Errorf("variant is %q, want %q", r.variant, variant.Classic)
+	}
+
+	for _, text := range []string{
+		"1. d3 d3\n",
+		"1. z9\n",
+		"Variant: chess\n",
+		"Start: bad\n",
+	} {
+		if _, err := readRecord(strings.NewReader(text)); err == nil {
+			t.Errorf("%q is read without an error", text)
+		}
+	}
+}
@@ -31,8 +31,13 @@ func main() {
 		log.Fatalln("OTHELLO_TOKEN or OTHELLO_MONGODB_URI environment variable is not set.")
 	}
 
+	retention, err := othellobot.RetentionConfigFromEnv()
+	if err != nil {
+		log.Fatalln("Error loading retention config:", err)
+	}
+
 	rand.Seed(time.Now().UnixNano())
 
-	bot := othellobot.New(token, mongodbURI)
+	bot := othellobot.New(token, mongodbURI, retention)
 	bot.Run()
 }
@@ -0,0 +1,234 @@
+// Command puzzlegen finds puzzles in the games saved by the bot and writes
+// them to a file, one JSON object per line, or imports such a file into the
+// database, where the bot takes its daily puzzles from:
+//
+//	puzzlegen -out puzzles.jsonl
+//	puzzlegen -import puzzles.jsonl
+//
+// The database is the one of OTHELLO_MONGODB_URI, like for the bot.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ArminGh02/othello-bot/pkg/database"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/puzzle"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	"github.com/joho/godotenv"
+)
+
+var (
+	out           = flag.String("out", "puzzles.jsonl", "the file the puzzles found are written to")
+	importFile    = flag.String("import", "", "import the puzzles of the file into the database instead of finding them")
+	workers       = flag.Int("workers", runtime.NumCPU(), "the number of games searched at the same time")
+	minDifficulty = flag.Int("min-difficulty", 1, "skip the puzzles rated easier than this, from 1 to 5")
+)
+
+// record is a puzzle in the files of puzzles.
+type record struct {
+	Position   string `json:"position"`
+	Solution   string `json:"solution"`
+	Margin     int    `json:"margin"`
+	NextBest   int    `json:"next_best"`
+	Difficulty int    `json:"difficulty"`
+	Hash       uint64 `json:"hash"`
+	GameID     string `json:"game_id,omitempty"`
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	// the environment variables may be set without a .env file
+	godotenv.Load()
+	mongodbURI := os.Getenv("OTHELLO_MONGODB_URI")
+	if mongodbURI == "" {
+		log.Fatalln("OTHELLO_MONGODB_URI environment variable is not set.")
+	}
+	db := database.New(mongodbURI)
+	defer db.Disconnect()
+
+	if *importFile != "" {
+		importPuzzles(db, *importFile)
+		return
+	}
+	findPuzzles(db, *out)
+}
+
+// findPuzzles searches the positions of the saved games for puzzles, and
+// writes the ones rated hard enough to the file, each position once.
+func findPuzzles(db *database.Handler, path string) {
+	games := make(chan *database.GameDoc)
+	found := make(chan record)
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for doc := range games {
+				for _, r := range puzzlesOf(doc) {
+					found <- r
+				}
+			}
+		}()
+	}
+
+	var gamesCount uint64
+	go func() {
+		db.ForEachGame(func(doc *database.GameDoc) {
+			atomic.AddUint64(&gamesCount, 1)
+			games <- doc
+		})
+		close(games)
+		wg.Wait()
+		close(found)
+	}()
+
+	seen := make(map[uint64]bool)
+	records := make([]record, 0)
+	for r := range found {
+		if seen[r.Hash] || r.Difficulty < *minDifficulty {
+			continue
+		}
+		seen[r.Hash] = true
+		records = append(records, r)
+	}
+	// the easier puzzles are used first
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Difficulty != records[j].Difficulty {
+			return records[i].Difficulty < records[j].Difficulty
+		}
+		return records[i].Hash < records[j].Hash
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalln(err)
+	}
+	log.Printf("Found %d puzzles in %d games.", len(records), atomic.LoadUint64(&gamesCount))
+}
+
+// puzzlesOf replays the game and returns the puzzles of the positions it
+// went through.
+func puzzlesOf(doc *database.GameDoc) []record {
+	if doc.Variant == string(variant.Anti) {
+		return nil
+	}
+	start, err := othellogame.ParsePosition(doc.StartPosition)
+	if err != nil {
+		// games saved before other start positions
+		start = othellogame.StandardPosition(othellogame.DefaultBoardSize, doc.WhiteStarts)
+	}
+	if start.Size() != othellogame.DefaultBoardSize {
+		return nil
+	}
+
+	game := othellogame.NewWithOptions(nil, nil, othellogame.Options{Start: start})
+	res := make([]record, 0)
+	for _, move := range doc.MoveSequence {
+		if move == othellogame.Pass {
+			continue
+		}
+		if p, ok := puzzle.Find(game); ok {
+			res = append(res, recordOf(p, doc.GameID))
+		}
+		if !game.IsLegalMove(move) {
+			log.Printf("Game %s has an illegal move %s.", doc.GameID, move)
+			break
+		}
+		game.PlaceDiskUnchecked(move)
+	}
+	return res
+}
+
+func recordOf(p *puzzle.Puzzle, gameID string) record {
+	return record{
+		Position:   p.Position.String(),
+		Solution:   p.Solution.String(),
+		Margin:     p.Margin,
+		NextBest:   p.NextBest,
+		Difficulty: p.Difficulty(),
+		Hash:       p.Hash(),
+		GameID:     gameID,
+	}
+}
+
+// importPuzzles saves the puzzles of the file to the database, checking
+// them with the solver again, as the file may be edited by hand.
+func importPuzzles(db *database.Handler, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer f.Close()
+
+	docs := make([]database.PuzzleDoc, 0)
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			log.Fatalf("Line %d: %v", lineNumber, err)
+		}
+		doc, err := docOf(r)
+		if err != nil {
+			log.Printf("Line %d is skipped: %v", lineNumber, err)
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalln(err)
+	}
+
+	imported := db.ImportPuzzles(docs)
+	log.Printf("Imported %d puzzles, %d were in the database already.", imported, len(docs)-imported)
+}
+
+func docOf(r record) (database.PuzzleDoc, error) {
+	position, err := othellogame.ParsePosition(r.Position)
+	if err != nil {
+		return database.PuzzleDoc{}, err
+	}
+	solution, err := coord.Parse(r.Solution, position.Size())
+	if err != nil {
+		return database.PuzzleDoc{}, err
+	}
+	game := othellogame.NewWithOptions(nil, nil, othellogame.Options{Start: position})
+	p, ok := puzzle.Find(game)
+	if !ok || p.Solution != solution {
+		return database.PuzzleDoc{}, fmt.Errorf("%s isn't the solution of a puzzle", r.Solution)
+	}
+	return database.PuzzleDoc{
+		Position:   p.Position.String(),
+		Solution:   p.Solution,
+		Margin:     p.Margin,
+		NextBest:   p.NextBest,
+		Difficulty: p.Difficulty(),
+		Hash:       int64(p.Hash()),
+		GameID:     r.GameID,
+	}, nil
+}
@@ -4,4 +4,9 @@ const (
 	BlackDiskEmoji = "⚫️"
 	WhiteDiskEmoji = "⚪️"
 	LegalMoveEmoji = "◯"
+	HoleEmoji      = "⬛️"
+
+	HintEmoji         = "💡"
+	SelectedMoveEmoji = "🎯"
+	FlipEmoji         = "🔄"
 )
@@ -2,9 +2,10 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"time"
 
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -16,32 +17,150 @@ type PlayerDoc struct {
 	Wins               int    `bson:"wins"`
 	Losses             int    `bson:"losses"`
 	Draws              int    `bson:"draws"`
+	AntiWins           int    `bson:"anti_wins"`
+	AntiLosses         int    `bson:"anti_losses"`
+	AntiDraws          int    `bson:"anti_draws"`
+	WhiteWins          int    `bson:"white_wins"`
+	WhiteLosses        int    `bson:"white_losses"`
+	WhiteDraws         int    `bson:"white_draws"`
+	BlackWins          int    `bson:"black_wins"`
+	BlackLosses        int    `bson:"black_losses"`
+	BlackDraws         int    `bson:"black_draws"`
 	LegalMovesAreShown bool   `bson:"legal_moves_are_shown"`
+	BoardIsImage       bool   `bson:"board_is_image"`
+	FlipsArePreviewed  bool   `bson:"flips_are_previewed"`
+	ConfirmsMoves      bool   `bson:"confirms_moves"`
+
+	// ReplaySpeed is the speed replays are sent with unless another is
+	// chosen, or empty for the default speed.
+	ReplaySpeed          string `bson:"replay_speed"`
+	SilentGameMessages   bool   `bson:"silent_game_messages"`
+	RejectsRematches     bool   `bson:"rejects_rematches"`
+	HiddenFromScoreboard bool   `bson:"hidden_from_scoreboard"`
+	// MoveTimeLimit is the number of seconds the opponent may think before
+	// the player can end the game, or 0 for the default limit.
+	MoveTimeLimit int `bson:"move_time_limit"`
+	// Language is the language the player chose, or empty for the
+	// language of their Telegram app.
+	Language string `bson:"language"`
+	// Theme is the disk theme the player chose, or empty for the classic one.
+	Theme string `bson:"theme"`
+
+	// PuzzlesTried and PuzzlesSolved count the daily puzzles the player
+	// answered, and PuzzleStreak the ones solved on consecutive days up to
+	// LastPuzzleDate, the date of the last one answered.
+	PuzzlesTried   int    `bson:"puzzles_tried"`
+	PuzzlesSolved  int    `bson:"puzzles_solved"`
+	PuzzleStreak   int    `bson:"puzzle_streak"`
+	LastPuzzleDate string `bson:"last_puzzle_date"`
+
+	// TutorialStep is the step of the tutorial the player is on, and
+	// TutorialCompleted is whether they made it to the end once.
+	TutorialStep      int  `bson:"tutorial_step"`
+	TutorialCompleted bool `bson:"tutorial_completed"`
+}
+
+// WinRate is the percentage of the classic games played that are won.
+func (doc *PlayerDoc) WinRate() int {
+	return percentage(doc.Wins, doc.Wins+doc.Draws+doc.Losses)
+}
+
+// WhiteWinRate is the percentage of the games played as white that are won.
+func (doc *PlayerDoc) WhiteWinRate() int {
+	return percentage(doc.WhiteWins, doc.WhiteGames())
+}
+
+// BlackWinRate is the percentage of the games played as black that are won.
+func (doc *PlayerDoc) BlackWinRate() int {
+	return percentage(doc.BlackWins, doc.BlackGames())
+}
+
+// PuzzleSolveRate is the percentage of the puzzles answered that are solved.
+func (doc *PlayerDoc) PuzzleSolveRate() int {
+	return percentage(doc.PuzzlesSolved, doc.PuzzlesTried)
 }
 
-func (doc *PlayerDoc) String(rank int) string {
-	winPercentage := 0
-	if matches := doc.Wins + doc.Draws + doc.Losses; matches > 0 {
-		winPercentage = int(100 * float64(doc.Wins) / float64(matches))
+// WhiteGames is the number of games of all variants played as white.
+func (doc *PlayerDoc) WhiteGames() int {
+	return doc.WhiteWins + doc.WhiteLosses + doc.WhiteDraws
+}
+
+// BlackGames is the number of games of all variants played as black.
+func (doc *PlayerDoc) BlackGames() int {
+	return doc.BlackWins + doc.BlackLosses + doc.BlackDraws
+}
+
+func percentage(part, whole int) int {
+	if whole == 0 {
+		return 0
 	}
-	return fmt.Sprintf(
-		"%s's Profile:\nRank: %d\nWins: %d\nLosses: %d\nDraws: %d\nWin Percentage: %d%%",
-		doc.Name,
-		rank,
-		doc.Wins,
-		doc.Losses,
-		doc.Draws,
-		winPercentage,
-	)
+	return int(100 * float64(part) / float64(whole))
 }
 
 func (doc *PlayerDoc) Score() int {
 	return 3*doc.Wins - doc.Losses
 }
 
+type GameDoc struct {
+	GameID          string        `bson:"game_id"`
+	MoveSequence    []coord.Coord `bson:"move_sequence"`
+	WhiteStarts     bool          `bson:"white_starts"`
+	BoardSize       int           `bson:"board_size"`
+	Variant         string        `bson:"variant"`
+	StartPosition   string        `bson:"start_position"`
+	Handicap        int           `bson:"handicap"`
+	RandomOpening   bool          `bson:"random_opening"`
+	Holes           string        `bson:"holes"`
+	WhitePlayerID   int64         `bson:"white_player_id"`
+	BlackPlayerID   int64         `bson:"black_player_id"`
+	WhitePlayerName string        `bson:"white_player_name"`
+	BlackPlayerName string        `bson:"black_player_name"`
+	WhiteScore      int           `bson:"white_score"`
+	BlackScore      int           `bson:"black_score"`
+	WhiteHintsUsed  int           `bson:"white_hints_used"`
+	BlackHintsUsed  int           `bson:"black_hints_used"`
+	FinishedAt      time.Time     `bson:"finished_at"`
+
+	// Opening is the name of the last opening of the book the game
+	// passed through, or empty if it didn't start with one.
+	Opening string `bson:"opening"`
+
+	// PositionHashes are the canonical hashes of the positions after each
+	// move, which the games that reached a position are counted by.
+	PositionHashes []int64 `bson:"position_hashes"`
+	// Fingerprint is the same for games that went through the same positions.
+	Fingerprint int64 `bson:"fingerprint"`
+	// DuplicateOf is the ID of an earlier game this one repeats, if any.
+	DuplicateOf string `bson:"duplicate_of"`
+
+	// ReplayFileIDs maps replay speeds and formats to the Telegram file IDs
+	// of the replays already sent for this game.
+	ReplayFileIDs map[string]string `bson:"replay_file_ids"`
+}
+
+// PuzzleDoc is the puzzle of a day, or an imported one for a day to come.
+type PuzzleDoc struct {
+	// Date is the day of the puzzle, like "2022-07-18", or empty for the
+	// imported puzzles that aren't used yet.
+	Date       string      `bson:"date"`
+	Position   string      `bson:"position"`
+	Solution   coord.Coord `bson:"solution"`
+	Margin     int         `bson:"margin"`
+	NextBest   int         `bson:"next_best"`
+	Difficulty int         `bson:"difficulty"`
+	// Hash is the canonical hash of the position, which the puzzles are
+	// deduplicated by.
+	Hash int64 `bson:"hash"`
+	// GameID is the ID of the game the puzzle is found in, or empty for
+	// the generated ones.
+	GameID string `bson:"game_id"`
+}
+
 type Handler struct {
-	client *mongo.Client
-	coll   *mongo.Collection
+	client  *mongo.Client
+	coll    *mongo.Collection
+	games   *mongo.Collection
+	puzzles *mongo.Collection
 }
 
 func New(uri string) *Handler {
@@ -49,14 +168,34 @@ func New(uri string) *Handler {
 	if err != nil {
 		log.Panicln(err)
 	}
-	coll := client.Database("othello_bot").Collection("players")
+	db := client.Database("othello_bot")
 
 	defer log.Println("Connected to MongoDB.")
 
-	return &Handler{
-		client: client,
-		coll:   coll,
+	handler := &Handler{
+		client:  client,
+		coll:    db.Collection("players"),
+		games:   db.Collection("games"),
+		puzzles: db.Collection("puzzles"),
 	}
+	handler.createIndexes()
+	return handler
+}
+
+// createIndexes creates the indexes of the fields games and puzzles are
+// looked up by, unless they exist already.
+func (db *Handler) createIndexes() {
+	_, err := db.games.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{"game_id", 1}}},
+		{Keys: bson.D{{"fingerprint", 1}}},
+		{Keys: bson.D{{"position_hashes", 1}}},
+	})
+	handleErr(err)
+	_, err = db.puzzles.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{"date", 1}}},
+		{Keys: bson.D{{"hash", 1}}},
+	})
+	handleErr(err)
 }
 
 func (db *Handler) AddPlayer(userID int64, name string) (added bool) {
@@ -108,13 +247,79 @@ func (db *Handler) LegalMovesAreShown(userID int64) bool {
 }
 
 func (db *Handler) ToggleLegalMovesAreShown(userID int64) {
-	update := bson.D{
-		{"$set", bson.D{
-			{"legal_moves_are_shown", !db.LegalMovesAreShown(userID)},
-		}},
-	}
-	_, err := db.coll.UpdateOne(context.TODO(), bson.D{{"user_id", userID}}, update)
-	handleErr(err)
+	db.setProperty("legal_moves_are_shown", !db.LegalMovesAreShown(userID), userID)
+}
+
+func (db *Handler) BoardIsImage(userID int64) bool {
+	return db.Find(userID).BoardIsImage
+}
+
+func (db *Handler) ToggleBoardIsImage(userID int64) {
+	db.setProperty("board_is_image", !db.BoardIsImage(userID), userID)
+}
+
+func (db *Handler) FlipsArePreviewed(userID int64) bool {
+	return db.Find(userID).FlipsArePreviewed
+}
+
+func (db *Handler) ToggleFlipsArePreviewed(userID int64) {
+	db.setProperty("flips_are_previewed", !db.FlipsArePreviewed(userID), userID)
+}
+
+func (db *Handler) ConfirmsMoves(userID int64) bool {
+	return db.Find(userID).ConfirmsMoves
+}
+
+func (db *Handler) ToggleConfirmsMoves(userID int64) {
+	db.setProperty("confirms_moves", !db.ConfirmsMoves(userID), userID)
+}
+
+func (db *Handler) SetReplaySpeed(userID int64, speed string) {
+	db.setProperty("replay_speed", speed, userID)
+}
+
+func (db *Handler) ToggleSilentGameMessages(userID int64) {
+	db.setProperty("silent_game_messages", !db.Find(userID).SilentGameMessages, userID)
+}
+
+func (db *Handler) ToggleRejectsRematches(userID int64) {
+	db.setProperty("rejects_rematches", !db.Find(userID).RejectsRematches, userID)
+}
+
+func (db *Handler) ToggleHiddenFromScoreboard(userID int64) {
+	db.setProperty("hidden_from_scoreboard", !db.Find(userID).HiddenFromScoreboard, userID)
+}
+
+func (db *Handler) SetMoveTimeLimit(userID int64, seconds int) {
+	db.setProperty("move_time_limit", seconds, userID)
+}
+
+// LanguageOf returns the language the player chose, which is empty for
+// players who haven't chosen one or aren't inserted yet.
+func (db *Handler) LanguageOf(userID int64) string {
+	return db.stringProperty("language", userID)
+}
+
+func (db *Handler) SetLanguage(userID int64, language string) {
+	db.setProperty("language", language, userID)
+}
+
+// ThemeOf returns the disk theme the player chose, which is empty for
+// players who haven't chosen one or aren't inserted yet.
+func (db *Handler) ThemeOf(userID int64) string {
+	return db.stringProperty("theme", userID)
+}
+
+func (db *Handler) SetTheme(userID int64, theme string) {
+	db.setProperty("theme", theme, userID)
+}
+
+func (db *Handler) SetTutorialStep(userID int64, step int) {
+	db.setProperty("tutorial_step", step, userID)
+}
+
+func (db *Handler) CompleteTutorial(userID int64) {
+	db.setProperty("tutorial_completed", true, userID)
 }
 
 func (db *Handler) IncrementWins(userID int64) {
@@ -129,6 +334,42 @@ func (db *Handler) IncrementDraws(userID int64) {
 	db.incrementProperty("draws", userID)
 }
 
+func (db *Handler) IncrementAntiWins(userID int64) {
+	db.incrementProperty("anti_wins", userID)
+}
+
+func (db *Handler) IncrementAntiLosses(userID int64) {
+	db.incrementProperty("anti_losses", userID)
+}
+
+func (db *Handler) IncrementAntiDraws(userID int64) {
+	db.incrementProperty("anti_draws", userID)
+}
+
+func (db *Handler) IncrementWhiteWins(userID int64) {
+	db.incrementProperty("white_wins", userID)
+}
+
+func (db *Handler) IncrementWhiteLosses(userID int64) {
+	db.incrementProperty("white_losses", userID)
+}
+
+func (db *Handler) IncrementWhiteDraws(userID int64) {
+	db.incrementProperty("white_draws", userID)
+}
+
+func (db *Handler) IncrementBlackWins(userID int64) {
+	db.incrementProperty("black_wins", userID)
+}
+
+func (db *Handler) IncrementBlackLosses(userID int64) {
+	db.incrementProperty("black_losses", userID)
+}
+
+func (db *Handler) IncrementBlackDraws(userID int64) {
+	db.incrementProperty("black_draws", userID)
+}
+
 func (db *Handler) incrementProperty(propertyName string, userID int64) {
 	update := bson.D{
 		{"$inc", bson.D{
@@ -139,6 +380,30 @@ func (db *Handler) incrementProperty(propertyName string, userID int64) {
 	handleErr(err)
 }
 
+// stringProperty returns the string property of the player, which is empty
+// for players who don't have it or aren't inserted yet.
+func (db *Handler) stringProperty(propertyName string, userID int64) string {
+	opts := options.FindOne().SetProjection(bson.D{{propertyName, 1}})
+	var doc bson.M
+	err := db.coll.FindOne(context.TODO(), bson.D{{"user_id", userID}}, opts).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return ""
+	}
+	handleErr(err)
+	res, _ := doc[propertyName].(string)
+	return res
+}
+
+func (db *Handler) setProperty(propertyName string, value interface{}, userID int64) {
+	update := bson.D{
+		{"$set", bson.D{
+			{propertyName, value},
+		}},
+	}
+	_, err := db.coll.UpdateOne(context.TODO(), bson.D{{"user_id", userID}}, update)
+	handleErr(err)
+}
+
 func (db *Handler) Find(userID int64) *PlayerDoc {
 	var doc PlayerDoc
 	err := db.coll.FindOne(context.TODO(), bson.D{{"user_id", userID}}).Decode(&doc)
@@ -146,6 +411,162 @@ func (db *Handler) Find(userID int64) *PlayerDoc {
 	return &doc
 }
 
+func (db *Handler) SaveGame(doc *GameDoc) {
+	_, err := db.games.InsertOne(context.TODO(), doc)
+	handleErr(err)
+}
+
+func (db *Handler) FindGame(gameID string) (doc *GameDoc, found bool) {
+	doc = &GameDoc{}
+	err := db.games.FindOne(context.TODO(), bson.D{{"game_id", gameID}}).Decode(doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, false
+	}
+	handleErr(err)
+	return doc, true
+}
+
+// FindGameByFingerprint returns the ID of the first game saved with the
+// fingerprint, if any.
+func (db *Handler) FindGameByFingerprint(fingerprint int64) (gameID string, found bool) {
+	var doc GameDoc
+	opts := options.FindOne().SetSort(bson.D{{"finished_at", 1}})
+	err := db.games.FindOne(context.TODO(), bson.D{{"fingerprint", fingerprint}}, opts).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return "", false
+	}
+	handleErr(err)
+	return doc.GameID, true
+}
+
+// CountGamesWithPosition returns the number of games, other than the
+// duplicates, that reached the position with the canonical hash.
+func (db *Handler) CountGamesWithPosition(hash int64) int {
+	count, err := db.games.CountDocuments(
+		context.TODO(),
+		bson.D{{"position_hashes", hash}, {"duplicate_of", ""}},
+	)
+	handleErr(err)
+	return int(count)
+}
+
+func (db *Handler) SetReplayFileID(gameID, key, fileID string) {
+	update := bson.D{
+		{"$set", bson.D{
+			{"replay_file_ids." + key, fileID},
+		}},
+	}
+	_, err := db.games.UpdateOne(context.TODO(), bson.D{{"game_id", gameID}}, update)
+	handleErr(err)
+}
+
+// RecordPuzzleAnswer counts the answer of the player to the puzzle of the
+// date, unless they answered it already, and returns whether it's counted.
+func (db *Handler) RecordPuzzleAnswer(userID int64, date string, solved bool, streak int) (recorded bool) {
+	solvedCount := 0
+	if solved {
+		solvedCount = 1
+	}
+	update := bson.D{
+		{"$inc", bson.D{
+			{"puzzles_tried", 1},
+			{"puzzles_solved", solvedCount},
+		}},
+		{"$set", bson.D{
+			{"puzzle_streak", streak},
+			{"last_puzzle_date", date},
+		}},
+	}
+	filter := bson.D{{"user_id", userID}, {"last_puzzle_date", bson.D{{"$ne", date}}}}
+	res, err := db.coll.UpdateOne(context.TODO(), filter, update)
+	handleErr(err)
+	return res.ModifiedCount > 0
+}
+
+// TopPuzzleSolvers returns at most n players who solved puzzles, the ones
+// who solved the most first.
+func (db *Handler) TopPuzzleSolvers(n int) []PlayerDoc {
+	opts := options.Find().
+		SetSort(bson.D{{"puzzles_solved", -1}, {"puzzle_streak", -1}}).
+		SetLimit(int64(n))
+	cur, err := db.coll.Find(context.TODO(), bson.D{{"puzzles_solved", bson.D{{"$gt", 0}}}}, opts)
+	handleErr(err)
+	res := make([]PlayerDoc, 0, n)
+	err = cur.All(context.TODO(), &res)
+	handleErr(err)
+	return res
+}
+
+// FindPuzzle returns the puzzle of the date, if it's made already.
+func (db *Handler) FindPuzzle(date string) (doc *PuzzleDoc, found bool) {
+	doc = &PuzzleDoc{}
+	err := db.puzzles.FindOne(context.TODO(), bson.D{{"date", date}}).Decode(doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, false
+	}
+	handleErr(err)
+	return doc, true
+}
+
+func (db *Handler) SavePuzzle(doc *PuzzleDoc) {
+	_, err := db.puzzles.InsertOne(context.TODO(), doc)
+	handleErr(err)
+}
+
+// ImportPuzzles saves the puzzles for the days to come, skipping the ones
+// with positions saved already, and returns the number of them saved.
+func (db *Handler) ImportPuzzles(docs []PuzzleDoc) (imported int) {
+	for i := range docs {
+		res, err := db.puzzles.UpdateOne(
+			context.TODO(),
+			bson.D{{"hash", docs[i].Hash}},
+			bson.D{{"$setOnInsert", &docs[i]}},
+			options.Update().SetUpsert(true),
+		)
+		handleErr(err)
+		if res.UpsertedCount > 0 {
+			imported++
+		}
+	}
+	return imported
+}
+
+// TakePuzzle makes the first imported puzzle that isn't used yet the puzzle
+// of the date, if there is one.
+func (db *Handler) TakePuzzle(date string) (doc *PuzzleDoc, found bool) {
+	doc = &PuzzleDoc{}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{"_id", 1}}).
+		SetReturnDocument(options.After)
+	err := db.puzzles.FindOneAndUpdate(
+		context.TODO(),
+		bson.D{{"date", ""}},
+		bson.D{{"$set", bson.D{{"date", date}}}},
+		opts,
+	).Decode(doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, false
+	}
+	handleErr(err)
+	return doc, true
+}
+
+// ForEachGame calls fn with each saved game other than the duplicates.
+func (db *Handler) ForEachGame(fn func(doc *GameDoc)) {
+	// games saved before duplicates were found have no duplicate_of
+	filter := bson.D{{"duplicate_of", bson.D{{"$in", bson.A{"", nil}}}}}
+	cur, err := db.games.Find(context.TODO(), filter)
+	handleErr(err)
+	defer cur.Close(context.TODO())
+	for cur.Next(context.TODO()) {
+		var doc GameDoc
+		err := cur.Decode(&doc)
+		handleErr(err)
+		fn(&doc)
+	}
+	handleErr(cur.Err())
+}
+
 func (db *Handler) Disconnect() {
 	if err := db.client.Disconnect(context.TODO()); err != nil {
 		log.Panicln(err)
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	"github.com/ArminGh02/othello-bot/resources"
+)
+
+// Book is a book of named openings. It knows the canonical hashes of the
+// positions the openings pass through rather than their moves, so the
+// openings are recognized however they are reached, on any of the
+// symmetries of the board and with either color moving first.
+type Book struct {
+	// positions maps the hashes of the positions in the book to the names
+	// of the openings ending at them, which are empty for the others.
+	positions map[uint64]string
+}
+
+var (
+	defaultBook     *Book
+	defaultBookOnce sync.Once
+)
+
+// DefaultBook returns the book of the openings in the resources.
+func DefaultBook() *Book {
+	defaultBookOnce.Do(func() {
+		f, err := resources.FS.Open("openings.txt")
+		if err != nil {
+			log.Panicln(err)
+		}
+		defer f.Close()
+
+		if defaultBook, err = ParseBook(f); err != nil {
+			log.Panicln(err)
+		}
+	})
+	return defaultBook
+}
+
+// ParseBook parses a book of lines like "Tiger: f5 d6 c3 d3 c4", which
+// are the moves of the openings from the standard position with black
+// moving first. Empty lines and the ones starting with "#" are skipped.
+func ParseBook(r io.Reader) (*Book, error) {
+	start := othellogame.StandardPosition(othellogame.DefaultBoardSize, false)
+	b := &Book{positions: make(map[uint64]string)}
+	b.add(start, "")
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, moves, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("line %d: missing the name of the opening", lineNumber)
+		}
+
+		game := newGameFrom(start)
+		fields := strings.Fields(moves)
+		for i, field := range fields {
+			move, err := coord.Parse(field, othellogame.DefaultBoardSize)
+			if err != nil || !game.IsLegalMove(move) {
+				return nil, fmt.Errorf("line %d: illegal move %q", lineNumber, field)
+			}
+			game.PlaceDiskUnchecked(move)
+
+			if i == len(fields)-1 {
+				b.add(game.Position(), strings.TrimSpace(name))
+			} else {
+				b.add(game.Position(), "")
+			}
+		}
+	}
+	return b, scanner.Err()
+}
+
+// add adds the position and the one with the colors of the disks swapped
+// to the book, naming them unless they are named already.
+func (b *Book) add(p *othellogame.Position, name string) {
+	for _, position := range [...]*othellogame.Position{p, colorsSwapped(p)} {
+		hash := position.CanonicalHash()
+		if b.positions[hash] == "" {
+			b.positions[hash] = name
+		}
+	}
+}
+
+func colorsSwapped(p *othellogame.Position) *othellogame.Position {
+	res := &othellogame.Position{
+		Board:       make([][]cell.Cell, len(p.Board)),
+		WhiteToMove: !p.WhiteToMove,
+	}
+	for y, row := range p.Board {
+		res.Board[y] = make([]cell.Cell, len(row))
+		for x, c := range row {
+			switch c {
+			case cell.Black:
+				res.Board[y][x] = cell.White
+			case cell.White:
+				res.Board[y][x] = cell.Black
+			default:
+				res.Board[y][x] = c
+			}
+		}
+	}
+	return res
+}
+
+// Opening returns the name of the last opening the game has passed through,
+// and whether its position is still in the book.
+func (b *Book) Opening(game *othellogame.Game) (name string, inBook bool) {
+	if game.Variant() == variant.Anti {
+		return "", false
+	}
+	if _, ok := b.positions[game.StartPosition().CanonicalHash()]; !ok {
+		return "", false
+	}
+	for _, hash := range game.PositionHashes() {
+		opening, ok := b.positions[hash]
+		if !ok {
+			return name, false
+		}
+		if opening != "" {
+			name = opening
+		}
+	}
+	return name, true
+}
+
+// Move returns one of the moves of the active player of game that keep
+// it in the book, chosen at random, or false if there is none.
+func (b *Book) Move(game *othellogame.Game) (coord.Coord, bool) {
+	if game.Variant() == variant.Anti || game.IsEnded() {
+		return coord.Coord{}, false
+	}
+	moves := make([]coord.Coord, 0)
+	for _, move := range game.LegalMoves() {
+		child := game.Clone()
+		child.PlaceDiskUnchecked(move)
+		if _, ok := b.positions[child.CanonicalHash()]; ok {
+			moves = append(moves, move)
+		}
+	}
+	if len(moves) == 0 {
+		return coord.Coord{}, false
+	}
+	return moves[rand.Intn(len(moves))], true
+}
+
+func newGameFrom(start *othellogame.Position) *othellogame.Game {
+	return othellogame.NewWithOptions(nil, nil, othellogame.Options{Start: start})
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+func playGame(t *testing.T, whiteStarts bool, moves ...string) *othellogame.Game {
+	t.Helper()
+	game := newGameFrom(othellogame.StandardPosition(othellogame.DefaultBoardSize, whiteStarts))
+	for _, s := range moves {
+		move, err := coord.Parse(s, othellogame.DefaultBoardSize)
+		if err != nil || !game.IsLegalMove(move) {
+			t.Fatalf("illegal move %q", s)
+		}
+		game.PlaceDiskUnchecked(move)
+	}
+	return game
+}
+
+func TestOpening(t *testing.T) {
+	book := DefaultBook()
+	tests := []struct {
+		name        string
+		whiteStarts bool
+		moves       []string
+		want        string
+		wantInBook  bool
+	}{
+		{"start", false, nil, "", true},
+		{"tiger", false, []string{"f5", "d6", "c3", "d3", "c4"}, "Tiger", true},
+		{"tiger on another symmetry", false, []string{"d3", "c5", "f6", "f5", "e6"}, "Tiger", true},
+		{"tiger with white first", true, []string{"c5", "e6", "f3", "e3", "f4"}, "Tiger", true},
+		{"perpendicular before tiger", false, []string{"f5", "d6", "c3"}, "Perpendicular opening", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := playGame(t, test.whiteStarts, test.moves...)
+			name, inBook := book.Opening(game)
+			if name != test.want || inBook != test.wantInBook {
+				t.Errorf("got (%q, %v), want (%q, %v)", name, inBook, test.want, test.wantInBook)
+			}
+		})
+	}
+}
+
+func TestOutOfBook(t *testing.T) {
+	book := DefaultBook()
+	game := playGame(t, false, "f5", "d6", "c3", "d3", "c4")
+	for _, move := range game.LegalMoves() {
+		child := game.Clone()
+		child.PlaceDiskUnchecked(move)
+		if name, inBook := book.Opening(child); !inBook {
+			if name != "Tiger" {
+				t.Errorf("got %q after leaving the book, want Tiger", name)
+			}
+			return
+		}
+	}
+	t.Fatal("every move after the tiger is in the book")
+}
+
+func TestBookMove(t *testing.T) {
+	game := playGame(t, false, "f5", "d6", "c3", "d3")
+	move, ok := DefaultBook().Move(game)
+	if !ok {
+		t.Fatal("no book move")
+	}
+	child := game.Clone()
+	child.PlaceDiskUnchecked(move)
+	if _, inBook := DefaultBook().Opening(child); !inBook {
+		t.Errorf("%v leaves the book", move)
+	}
+}
+
+func TestParseBook(t *testing.T) {
+	if _, err := ParseBook(strings.NewReader("Bad: f5 a1")); err == nil {
+		t.Error("illegal move parsed")
+	}
+	if _, err := ParseBook(strings.NewReader("f5 d6")); err == nil {
+		t.Error("opening without a name parsed")
+	}
+}
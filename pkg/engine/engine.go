@@ -0,0 +1,297 @@
+// Package engine searches the moves of othello games for the best one.
+package engine
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+const (
+	// winScore is more than any evaluation of an unfinished game,
+	// so won games are always preferred.
+	winScore = 1 << 20
+
+	mobilityWeight = 8
+)
+
+// Evaluation is a way to score the positions the search stops at.
+type Evaluation string
+
+const (
+	// Standard weighs the cells of the disks and the mobility of the
+	// active player.
+	Standard = Evaluation("standard")
+	// Positional weighs the cells of the disks only.
+	Positional = Evaluation("positional")
+	// Greedy counts the disks, like beginners do.
+	Greedy = Evaluation("greedy")
+)
+
+var Evaluations = [...]Evaluation{Standard, Positional, Greedy}
+
+func ParseEvaluation(s string) (Evaluation, bool) {
+	for _, e := range Evaluations {
+		if string(e) == s {
+			return e, true
+		}
+	}
+	return Standard, false
+}
+
+// Options configure the search of BestMoveWithOptions.
+type Options struct {
+	// Depth is how many moves ahead the search goes.
+	Depth int
+
+	// Evaluation scores the positions at Depth, which is Standard if empty.
+	Evaluation Evaluation
+
+	// NoBook searches the openings too instead of playing the moves of
+	// the book of openings.
+	NoBook bool
+}
+
+// BestMove plays a move of the book of openings while game is in it, or
+// searches the moves of the active player of game depth moves ahead and
+// returns the best one. It returns false if the game has ended.
+// The game isn't changed.
+func BestMove(game *othellogame.Game, depth int) (coord.Coord, bool) {
+	return BestMoveWithOptions(game, Options{Depth: depth})
+}
+
+// BestMoveWithOptions is like BestMove with the search configured by opts.
+func BestMoveWithOptions(game *othellogame.Game, opts Options) (coord.Coord, bool) {
+	if game.IsEnded() {
+		return coord.Coord{}, false
+	}
+	if !opts.NoBook {
+		if move, ok := DefaultBook().Move(game); ok {
+			return move, true
+		}
+	}
+
+	s := newSearch(game)
+	if opts.Evaluation != "" {
+		s.evaluation = opts.Evaluation
+	}
+	moves := s.orderedMoves(game)
+	white := game.WhiteToMove()
+	best := moves[0]
+	alpha, beta := math.MinInt, math.MaxInt
+	for _, move := range moves {
+		child := game.Clone()
+		child.PlaceDiskUnchecked(move)
+		score := s.minimax(child, opts.Depth-1, alpha, beta)
+		if white && score > alpha {
+			best, alpha = move, score
+		} else if !white && score < beta {
+			best, beta = move, score
+		}
+	}
+	return best, true
+}
+
+// Evaluate returns how good the position of game is for white, from the
+// final score of ended games and the disks and mobility of others.
+func Evaluate(game *othellogame.Game) int {
+	return newSearch(game).evaluate(game)
+}
+
+type search struct {
+	weights    [][]int
+	anti       bool
+	evaluation Evaluation
+	// table is the transposition table, which has the scores of the
+	// positions searched already by their canonical hashes, as positions
+	// that are the same up to the symmetries of the board have the same
+	// score.
+	table map[uint64]tableEntry
+}
+
+type bound int
+
+const (
+	exact bound = iota
+	// lowerBound scores are of searches cut off by beta,
+	// which the score is at least.
+	lowerBound
+	// upperBound scores are of searches that didn't reach alpha,
+	// which the score is at most.
+	upperBound
+)
+
+type tableEntry struct {
+	depth int
+	score int
+	bound bound
+}
+
+func newSearch(game *othellogame.Game) *search {
+	return &search{
+		weights:    weightsOf(game.Size()),
+		anti:       game.Variant() == variant.Anti,
+		evaluation: Standard,
+		table:      make(map[uint64]tableEntry),
+	}
+}
+
+// minimax returns the score of game for white with alpha-beta pruning. Passes
+// don't need special care, as the turn is passed by placing disks already.
+func (s *search) minimax(game *othellogame.Game, depth, alpha, beta int) int {
+	if game.IsEnded() || depth <= 0 {
+		return s.evaluate(game)
+	}
+
+	hash := game.CanonicalHash()
+	if entry, ok := s.table[hash]; ok && entry.depth >= depth {
+		switch {
+		case entry.bound == exact:
+			return entry.score
+		case entry.bound == lowerBound && entry.score >= beta:
+			return entry.score
+		case entry.bound == upperBound && entry.score <= alpha:
+			return entry.score
+		}
+	}
+	alphaBefore, betaBefore := alpha, beta
+
+	white := game.WhiteToMove()
+	best := math.MaxInt
+	if white {
+		best = math.MinInt
+	}
+	for _, move := range s.orderedMoves(game) {
+		child := game.Clone()
+		child.PlaceDiskUnchecked(move)
+		score := s.minimax(child, depth-1, alpha, beta)
+		if white {
+			if score > best {
+				best = score
+			}
+			if best > alpha {
+				alpha = best
+			}
+		} else {
+			if score < best {
+				best = score
+			}
+			if best < beta {
+				beta = best
+			}
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	entry := tableEntry{depth: depth, score: best, bound: exact}
+	switch {
+	case best <= alphaBefore:
+		entry.bound = upperBound
+	case best >= betaBefore:
+		entry.bound = lowerBound
+	}
+	s.table[hash] = entry
+	return best
+}
+
+func (s *search) evaluate(game *othellogame.Game) int {
+	diskDifference := game.WhiteDisks() - game.BlackDisks()
+	if s.anti {
+		diskDifference = -diskDifference
+	}
+	if game.IsEnded() {
+		switch {
+		case diskDifference > 0:
+			return winScore + diskDifference
+		case diskDifference < 0:
+			return -winScore + diskDifference
+		default:
+			return 0
+		}
+	}
+	if s.evaluation == Greedy {
+		return diskDifference
+	}
+
+	positional := 0
+	for y, row := range game.Board() {
+		for x, c := range row {
+			switch c {
+			case cell.White:
+				positional += s.weights[y][x]
+			case cell.Black:
+				positional -= s.weights[y][x]
+			}
+		}
+	}
+	// the cells worth taking in othello are worth giving away in anti-othello
+	if s.anti {
+		positional = -positional
+	}
+	if s.evaluation == Positional {
+		return positional
+	}
+
+	mobility := mobilityWeight * len(game.LegalMoves())
+	if !game.WhiteToMove() {
+		mobility = -mobility
+	}
+	return positional + mobility
+}
+
+// orderedMoves returns the legal moves of game with the ones on better
+// cells first, which prunes more of the search.
+func (s *search) orderedMoves(game *othellogame.Game) []coord.Coord {
+	moves := game.LegalMoves()
+	sign := 1
+	if s.anti {
+		sign = -1
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return sign*s.weights[moves[i].Y][moves[i].X] > sign*s.weights[moves[j].Y][moves[j].X]
+	})
+	return moves
+}
+
+// weightsOf returns how much having a disk on each cell of a board of
+// size×size cells is worth: corners can never be flipped, and the cells
+// next to them give them away.
+func weightsOf(size int) [][]int {
+	last := size - 1
+	res := make([][]int, size)
+	for y := range res {
+		res[y] = make([]int, size)
+		for x := range res[y] {
+			fromEdgeX := min(x, last-x)
+			fromEdgeY := min(y, last-y)
+			switch {
+			case fromEdgeX == 0 && fromEdgeY == 0:
+				res[y][x] = 100
+			case fromEdgeX == 1 && fromEdgeY == 1:
+				res[y][x] = -50
+			case fromEdgeX+fromEdgeY == 1:
+				res[y][x] = -20
+			case fromEdgeX == 0 || fromEdgeY == 0:
+				res[y][x] = 10
+			case fromEdgeX == 1 || fromEdgeY == 1:
+				res[y][x] = -5
+			default:
+				res[y][x] = 1
+			}
+		}
+	}
+	return res
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
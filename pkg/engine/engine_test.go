@@ -0,0 +1,47 @@
+package engine
+
+import "testing"
+
+func TestGreedyFlipsMostDisks(t *testing.T) {
+	games := [][]string{
+		{"f5"},
+		{"f5", "d6", "c3", "d3", "c4"},
+		{"f5", "d6", "c3", "d3", "c4", "f4", "c5", "b3", "c2"},
+	}
+	for _, moves := range games {
+		game := playGame(t, false, moves...)
+		mostFlips := 0
+		for _, move := range game.LegalMoves() {
+			if flips := len(game.Flips(move)); flips > mostFlips {
+				mostFlips = flips
+			}
+		}
+
+		move, ok := BestMoveWithOptions(game, Options{Depth: 1, Evaluation: Greedy, NoBook: true})
+		if !ok || !game.IsLegalMove(move) {
+			t.Fatalf("after %v: got %v, which is not a legal move", moves, move)
+		}
+		if flips := len(game.Flips(move)); flips != mostFlips {
+			t.Errorf("after %v: %v flips %d disks, want %d", moves, move, flips, mostFlips)
+		}
+	}
+}
+
+func TestEvaluations(t *testing.T) {
+	game := playGame(t, false, "f5", "d6", "c3", "d3", "c4")
+	for _, e := range Evaluations {
+		parsed, ok := ParseEvaluation(string(e))
+		if !ok || parsed != e {
+			t.Errorf("ParseEvaluation(%q) = %q, %v", e, parsed, ok)
+		}
+		for depth := 1; depth <= 3; depth++ {
+			move, ok := BestMoveWithOptions(game, Options{Depth: depth, Evaluation: e, NoBook: true})
+			if !ok || !game.IsLegalMove(move) {
+				t.Errorf("%s at depth %d: got %v, which is not a legal move", e, depth, move)
+			}
+		}
+	}
+	if _, ok := ParseEvaluation("random"); ok {
+		t.Error(`ParseEvaluation("random") succeeded`)
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+// Solve returns the number of disks white wins game by, which is negative
+// if black wins, when both players play perfectly to the end. In
+// anti-othello it's the number of disks white has fewer. The search goes
+// to the end of the game, so it's only fast for positions with at most
+// about a dozen empty cells.
+func Solve(game *othellogame.Game) int {
+	return margin(newSearch(game).minimax(game, emptyCells(game), math.MinInt, math.MaxInt))
+}
+
+// SolveMoves returns the number of disks the active player of game wins
+// by after each of their legal moves, which is negative if they lose,
+// when both players play perfectly after it.
+func SolveMoves(game *othellogame.Game) map[coord.Coord]int {
+	res := make(map[coord.Coord]int)
+	if game.IsEnded() {
+		return res
+	}
+	s := newSearch(game)
+	depth := emptyCells(game)
+	for _, move := range s.orderedMoves(game) {
+		child := game.Clone()
+		child.PlaceDiskUnchecked(move)
+		score := margin(s.minimax(child, depth-1, math.MinInt, math.MaxInt))
+		if !game.WhiteToMove() {
+			score = -score
+		}
+		res[move] = score
+	}
+	return res
+}
+
+// margin returns the disk difference of the score of an ended game.
+func margin(score int) int {
+	switch {
+	case score > winScore/2:
+		return score - winScore
+	case score < -winScore/2:
+		return score + winScore
+	default:
+		return score
+	}
+}
+
+// emptyCells returns the number of empty cells of game, which is the most
+// moves left in it, as every move fills one of them.
+func emptyCells(game *othellogame.Game) int {
+	res := 0
+	for _, row := range game.Board() {
+		for _, c := range row {
+			if c == cell.Empty {
+				res++
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+)
+
+// lateGame plays random moves from the standard position until empty
+// cells are left, unless the game ends before.
+func lateGame(rnd *rand.Rand, empty int) *othellogame.Game {
+	game := newGameFrom(othellogame.StandardPosition(othellogame.DefaultBoardSize, false))
+	for !game.IsEnded() && emptyCells(game) > empty {
+		moves := game.LegalMoves()
+		game.PlaceDiskUnchecked(moves[rnd.Intn(len(moves))])
+	}
+	return game
+}
+
+// plainSolve searches every move to the end without pruning.
+func plainSolve(game *othellogame.Game) int {
+	if game.IsEnded() {
+		return game.WhiteDisks() - game.BlackDisks()
+	}
+	white := game.WhiteToMove()
+	best := 0
+	for i, move := range game.LegalMoves() {
+		child := game.Clone()
+		child.PlaceDiskUnchecked(move)
+		score := plainSolve(child)
+		if i == 0 || white && score > best || !white && score < best {
+			best = score
+		}
+	}
+	return best
+}
+
+func TestSolve(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		game := lateGame(rnd, 7)
+		want := plainSolve(game)
+		if got := Solve(game); got != want {
+			t.Fatalf("Solve(%s) = %d, want %d", game.Position(), got, want)
+		}
+		if game.IsEnded() {
+			continue
+		}
+
+		best, first := 0, true
+		for move, score := range SolveMoves(game) {
+			child := game.Clone()
+			child.PlaceDiskUnchecked(move)
+			want := plainSolve(child)
+			if !game.WhiteToMove() {
+				want = -want
+			}
+			if score != want {
+				t.Fatalf("SolveMoves(%s)[%v] = %d, want %d", game.Position(), move, score, want)
+			}
+			if first || score > best {
+				best, first = score, false
+			}
+		}
+		if !game.WhiteToMove() {
+			best = -best
+		}
+		if best != want {
+			t.Errorf("the best move of %s wins by %d, want %d", game.Position(), best, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package gifmaker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// encodeAPNG encodes frames as an animated PNG, which unlike GIF keeps
+// the full colors of the board. Each frame except the first only
+// contains the part that differs from the previous frame.
+func encodeAPNG(w io.Writer, frames []frame, opts Options) error {
+	if _, err := io.WriteString(w, pngSignature); err != nil {
+		return err
+	}
+
+	var (
+		prev     *image.RGBA
+		header   []byte
+		sequence uint32
+	)
+	for i := range frames {
+		img := frames[i].rgba(opts)
+		region := img.Bounds()
+		if prev != nil {
+			region = changedBounds(prev, img)
+			if region.Empty() {
+				region = image.Rect(0, 0, 1, 1)
+			}
+		}
+		prev = img
+
+		chunks, err := encodePNGChunks(img.SubImage(region))
+		if err != nil {
+			return err
+		}
+
+		if header == nil {
+			header = chunks[0].data
+			err = writePNGChunks(
+				w,
+				chunks[0],
+				pngChunk{"acTL", uint32s(uint32(len(frames)), 0)},
+			)
+			if err != nil {
+				return err
+			}
+		} else if !bytes.Equal(header[8:], chunks[0].data[8:]) {
+			return errors.New("frames of an APNG must have the same color type")
+		}
+
+		fcTL := pngChunk{"fcTL", append(
+			uint32s(
+				sequence,
+				uint32(region.Dx()),
+				uint32(region.Dy()),
+				uint32(region.Min.X),
+				uint32(region.Min.Y),
+			),
+			uint16s(uint16(frames[i].delay), 100)...,
+		)}
+		// dispose_op none and blend_op source
+		fcTL.data = append(fcTL.data, 0, 0)
+		sequence++
+		if err := writePNGChunks(w, fcTL); err != nil {
+			return err
+		}
+
+		for _, chunk := range chunks {
+			if chunk.typ != "IDAT" {
+				continue
+			}
+			if i > 0 {
+				chunk = pngChunk{"fdAT", append(uint32s(sequence), chunk.data...)}
+				sequence++
+			}
+			if err := writePNGChunks(w, chunk); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writePNGChunks(w, pngChunk{"IEND", nil})
+}
+
+func encodePNGChunks(img image.Image) ([]pngChunk, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	b := buf.Bytes()[len(pngSignature):]
+	res := make([]pngChunk, 0)
+	for len(b) >= 12 {
+		length := binary.BigEndian.Uint32(b)
+		res = append(res, pngChunk{
+			typ:  string(b[4:8]),
+			data: b[8 : 8+length],
+		})
+		b = b[12+length:]
+	}
+	return res, nil
+}
+
+func writePNGChunks(w io.Writer, chunks ...pngChunk) error {
+	for _, chunk := range chunks {
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(chunk.typ))
+		crc.Write(chunk.data)
+
+		_, err := w.Write(append(uint32s(uint32(len(chunk.data))), chunk.typ...))
+		if err == nil {
+			_, err = w.Write(chunk.data)
+		}
+		if err == nil {
+			_, err = w.Write(uint32s(crc.Sum32()))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func uint32s(values ...uint32) []byte {
+	res := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(res[4*i:], v)
+	}
+	return res
+}
+
+func uint16s(values ...uint16) []byte {
+	res := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint16(res[2*i:], v)
+	}
+	return res
+}
@@ -0,0 +1,104 @@
+package gifmaker
+
+import (
+	"image"
+	"image/gif"
+	"io"
+)
+
+func encodeGIF(w io.Writer, frames []frame, opts Options) error {
+	images := make([]*image.Paletted, len(frames))
+	delays := make([]int, len(frames))
+	disposal := make([]byte, len(frames))
+	for i := range frames {
+		images[i] = frames[i].paletted(opts)
+		delays[i] = frames[i].delay
+		disposal[i] = gif.DisposalNone
+	}
+
+	return gif.EncodeAll(w, &gif.GIF{
+		Image:    cropToChanges(images),
+		Delay:    delays,
+		Disposal: disposal,
+		Config: image.Config{
+			ColorModel: replayPalette,
+			Width:      boardPNG.Bounds().Dx(),
+			Height:     boardPNG.Bounds().Dy(),
+		},
+	})
+}
+
+// cropToChanges replaces each frame except the first with the smallest part
+// of it that differs from the previous frame, with the unchanged pixels of
+// that part made transparent so that they compress well. With DisposalNone
+// the rest of the previous frame stays visible.
+func cropToChanges(frames []*image.Paletted) []*image.Paletted {
+	res := make([]*image.Paletted, len(frames))
+	res[0] = frames[0]
+	for i := 1; i < len(frames); i++ {
+		prev, next := frames[i-1], frames[i]
+		changed := changedBounds(prev, next)
+		if changed.Empty() {
+			changed = image.Rect(0, 0, 1, 1)
+		}
+
+		diff := image.NewPaletted(changed, next.Palette)
+		for y := changed.Min.Y; y < changed.Max.Y; y++ {
+			for x := changed.Min.X; x < changed.Max.X; x++ {
+				index := next.ColorIndexAt(x, y)
+				if index == prev.ColorIndexAt(x, y) {
+					index = transparentIndex
+				}
+				diff.SetColorIndex(x, y, index)
+			}
+		}
+		res[i] = diff
+	}
+	return res
+}
+
+// changedBounds returns the smallest rectangle containing
+// all the pixels that differ between prev and next.
+func changedBounds(prev, next image.Image) image.Rectangle {
+	bounds := next.Bounds()
+	minX, minY, maxX, maxY := bounds.Max.X, bounds.Max.Y, bounds.Min.X, bounds.Min.Y
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if samePixel(prev, next, x, y) {
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x >= maxX {
+				maxX = x + 1
+			}
+			if y < minY {
+				minY = y
+			}
+			if y >= maxY {
+				maxY = y + 1
+			}
+		}
+	}
+	if minX >= maxX {
+		return image.Rectangle{}
+	}
+	return image.Rect(minX, minY, maxX, maxY)
+}
+
+func samePixel(a, b image.Image, x, y int) bool {
+	switch a := a.(type) {
+	case *image.Paletted:
+		return a.ColorIndexAt(x, y) == b.(*image.Paletted).ColorIndexAt(x, y)
+	case *image.RGBA:
+		b := b.(*image.RGBA)
+		i, j := a.PixOffset(x, y), b.PixOffset(x, y)
+		return a.Pix[i] == b.Pix[j] &&
+			a.Pix[i+1] == b.Pix[j+1] &&
+			a.Pix[i+2] == b.Pix[j+2] &&
+			a.Pix[i+3] == b.Pix[j+3]
+	default:
+		return a.At(x, y) == b.At(x, y)
+	}
+}
@@ -0,0 +1,69 @@
+package gifmaker
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"os/exec"
+)
+
+// frameSequenceRate is the frame rate of frame sequences in frames per second.
+// Frames are repeated to last their delay, which is a multiple of
+// transitionFrameDelay in all replays.
+const frameSequenceRate = 100 / transitionFrameDelay
+
+func writeFrameSequence(w io.Writer, frames []frame, opts Options) error {
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	for i := range frames {
+		var buf bytes.Buffer
+		if err := encoder.Encode(&buf, frames[i].rgba(opts)); err != nil {
+			return err
+		}
+
+		repeats := frames[i].delay / transitionFrameDelay
+		if repeats < 1 {
+			repeats = 1
+		}
+		for j := 0; j < repeats; j++ {
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeMP4 pipes the frame sequence of a replay into ffmpeg.
+func encodeMP4(w io.Writer, frames []frame, opts Options) error {
+	cmd := exec.Command(
+		"ffmpeg",
+		"-loglevel", "error",
+		"-f", "image2pipe",
+		"-framerate", fmt.Sprint(frameSequenceRate),
+		"-i", "-",
+		"-c:v", "libx264",
+		"-pix_fmt", "yuv420p",
+		"-movflags", "frag_keyframe+empty_moov",
+		"-f", "mp4",
+		"-",
+	)
+	var stderr bytes.Buffer
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	writeErr := writeFrameSequence(stdin, frames, opts)
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, stderr.String())
+	}
+	return writeErr
+}
@@ -0,0 +1,87 @@
+package gifmaker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+)
+
+const (
+	webpAnimationFlag = 0x02
+	webpNoBlendFlag   = 0x02
+)
+
+// encodeWebP encodes frames as a lossless animated WebP. Like in APNGs,
+// each frame except the first only contains the part of it that differs
+// from the previous frame, though WebP needs the parts to start at even
+// coordinates.
+func encodeWebP(w io.Writer, frames []frame, opts Options) error {
+	bounds := boardPNG.Bounds()
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpAnimationFlag
+	putUint24(vp8x[4:], uint32(bounds.Dx()-1))
+	putUint24(vp8x[7:], uint32(bounds.Dy()-1))
+	writeRIFFChunk(&body, "VP8X", vp8x)
+
+	// transparent black background, looping forever
+	writeRIFFChunk(&body, "ANIM", make([]byte, 6))
+
+	var prev *image.RGBA
+	for i := range frames {
+		img := frames[i].rgba(opts)
+		region := img.Bounds()
+		if prev != nil {
+			region = changedBounds(prev, img)
+			if region.Empty() {
+				region = image.Rect(0, 0, 1, 1)
+			}
+			region.Min.X &^= 1
+			region.Min.Y &^= 1
+		}
+		prev = img
+
+		var anmf bytes.Buffer
+		header := make([]byte, 16)
+		putUint24(header[0:], uint32(region.Min.X/2))
+		putUint24(header[3:], uint32(region.Min.Y/2))
+		putUint24(header[6:], uint32(region.Dx()-1))
+		putUint24(header[9:], uint32(region.Dy()-1))
+		putUint24(header[12:], uint32(frames[i].delay*10))
+		header[15] = webpNoBlendFlag
+		anmf.Write(header)
+		writeRIFFChunk(&anmf, "VP8L", encodeVP8L(img.SubImage(region).(*image.RGBA)))
+
+		writeRIFFChunk(&body, "ANMF", anmf.Bytes())
+	}
+
+	riff := make([]byte, 8)
+	copy(riff, "RIFF")
+	binary.LittleEndian.PutUint32(riff[4:], uint32(body.Len()))
+	if _, err := w.Write(riff); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+func writeRIFFChunk(buf *bytes.Buffer, fourCC string, data []byte) {
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(data)))
+	buf.WriteString(fourCC)
+	buf.Write(size)
+	buf.Write(data)
+	if len(data)%2 == 1 {
+		buf.WriteByte(0)
+	}
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+}
@@ -0,0 +1,145 @@
+package gifmaker
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sprite is a disk on the board, scaled down while it is placed or flipped.
+type sprite struct {
+	where  coord.Coord
+	color  cell.Cell
+	scaleX float64
+	scaleY float64
+}
+
+// frame describes what a frame of a replay shows,
+// independent of the kind of image it is rendered to.
+type frame struct {
+	disks    []sprite
+	flipped  []coord.Coord
+	lastMove *coord.Coord
+	overlay  string
+
+	// delay is in 100ths of a second.
+	delay int
+}
+
+func getGameFrames(movesSequence []coord.Coord, whiteStarts bool, opts Options) []frame {
+	game := newReplayGame(opts)
+	game.SetTurn(whiteStarts)
+
+	res := make([]frame, 0, len(movesSequence)+1)
+	res = append(res, getGameFrame(game, opts, nil))
+	for _, move := range movesSequence {
+		if move == othellogame.Pass {
+			// the replayed game passes by itself, also for the sequences
+			// stored before passes were recorded
+			continue
+		}
+		before := copyBoard(game.Board())
+		moveNumber := game.MovesCount()
+		game.PlaceDiskUnchecked(move)
+		flipped := flippedDisks(before, game.Board())
+
+		if opts.AnimateFlips {
+			placed := game.Board()[move.Y][move.X]
+			res = append(res, getTransitionFrames(before, move, placed, flipped, moveNumber, opts)...)
+		}
+
+		res = append(res, getGameFrame(game, opts, flipped))
+	}
+
+	if last := &res[len(res)-1]; opts.FinalFrameDelay > last.delay {
+		last.delay = opts.FinalFrameDelay
+	}
+	return res
+}
+
+func newReplayGame(opts Options) *othellogame.Game {
+	gameOpts := othellogame.DefaultOptions()
+	gameOpts.BoardSize = opts.boardSize()
+	gameOpts.Start = opts.Start
+	return othellogame.NewWithOptions(&tgbotapi.User{}, &tgbotapi.User{}, gameOpts)
+}
+
+func getGameFrame(game *othellogame.Game, opts Options, flipped []coord.Coord) frame {
+	res := frame{
+		disks: boardSprites(game.Board()),
+		delay: opts.Speed.Delay(),
+	}
+
+	if opts.HighlightFlips {
+		res.flipped = flipped
+	}
+
+	if lastMove, ok := game.LastMove(); opts.MarkLastMove && ok {
+		res.lastMove = &lastMove
+	}
+
+	if opts.ShowOverlay {
+		moves := game.MovesCount()
+		res.overlay = overlayText(moves, game.WhiteDisks(), game.BlackDisks())
+	}
+	return res
+}
+
+func boardSprites(board [][]cell.Cell) []sprite {
+	res := make([]sprite, 0)
+	for y := range board {
+		for x, c := range board[y] {
+			if c != cell.Empty {
+				res = append(res, sprite{coord.New(x, y), c, 1, 1})
+			}
+		}
+	}
+	return res
+}
+
+func (f *frame) paletted(opts Options) *image.Paletted {
+	l := layoutOf(opts.boardSize(), opts.Theme)
+	base := l.boardPaletted
+	if !opts.ShowCoordinates {
+		base = l.boardNoCoordinatesPaletted
+	}
+
+	res := cloneImage(base)
+	for _, disk := range f.disks {
+		drawTile(res, diskTile(l, base, disk))
+	}
+	f.drawMarkers(res, l)
+	return res
+}
+
+func (f *frame) rgba(opts Options) *image.RGBA {
+	l := layoutOf(opts.boardSize(), opts.Theme)
+	base := l.board
+	if !opts.ShowCoordinates {
+		base = l.boardNoCoordinates
+	}
+
+	res := image.NewRGBA(base.Bounds())
+	draw.Draw(res, res.Bounds(), base, image.Point{}, draw.Src)
+	for _, disk := range f.disks {
+		drawDiskScaled(res, l, disk)
+	}
+	f.drawMarkers(res, l)
+	return res
+}
+
+func (f *frame) drawMarkers(dst draw.Image, l *layout) {
+	for _, c := range f.flipped {
+		drawCircle(dst, l.diskCenter(c), l.markerRadius(4), flippedDiskColor)
+	}
+	if f.lastMove != nil {
+		drawCircle(dst, l.diskCenter(*f.lastMove), l.markerRadius(6), lastMoveColor)
+	}
+	if f.overlay != "" {
+		drawOverlay(dst, f.overlay)
+	}
+}
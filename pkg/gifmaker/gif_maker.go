@@ -1,85 +1,88 @@
 package gifmaker
 
 import (
+	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
-	"image/gif"
-	"log"
-	"os"
+	"io"
 
-	"github.com/ArminGh02/othello-bot/pkg/othellogame"
 	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
 	"github.com/ArminGh02/othello-bot/pkg/util/coord"
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 var (
 	cellToImage = map[cell.Cell]image.Image{
-		cell.White: readPNG("resources/white-disk.png"),
-		cell.Black: readPNG("resources/black-disk.png"),
+		cell.White: readPNG("white-disk.png"),
+		cell.Black: readPNG("black-disk.png"),
+	}
+	boardPNG                = readPNG("board.png")
+	boardPNGNoCoordinates   = withoutCoordinates(boardPNG)
+	flippedDiskColor        = image.NewUniform(color.RGBA{R: 250, G: 200, B: 30, A: 255})
+	overlayColor            = image.NewUniform(color.White)
+	overlayOrigin           = image.Pt(250, 52)
+	coordinatesLabelsBounds = [...]image.Rectangle{
+		image.Rect(86, 84, 412, 116), // files
+		image.Rect(46, 126, 70, 464), // ranks
 	}
-	boardImage = imageToPaletted(readPNG("resources/board.png"))
 )
 
-func Make(outputFilename string, movesSequence []coord.Coord, whiteStarts bool) {
-	frames := getGameFrames(movesSequence, whiteStarts)
-	delays := make([]int, len(frames))
-	for i := range delays {
-		delays[i] = 200
+// Make encodes the replay of a game into w in the format of opts.
+func Make(w io.Writer, movesSequence []coord.Coord, whiteStarts bool, opts Options) error {
+	if opts.Format == FormatText {
+		return writeTranscript(w, movesSequence, whiteStarts, opts)
 	}
 
-	out, err := os.Create(outputFilename)
-	if err != nil {
-		log.Panicln(err)
+	frames := getGameFrames(movesSequence, whiteStarts, opts)
+	switch opts.Format {
+	case FormatGIF, "":
+		return encodeGIF(w, frames, opts)
+	case FormatAPNG:
+		return encodeAPNG(w, frames, opts)
+	case FormatWebP:
+		return encodeWebP(w, frames, opts)
+	case FormatMP4:
+		return encodeMP4(w, frames, opts)
+	default:
+		return fmt.Errorf("unknown replay format %q", opts.Format)
 	}
-	defer out.Close()
-	gif.EncodeAll(out, &gif.GIF{
-		Image: frames,
-		Delay: delays,
-	})
 }
 
-func getGameFrames(movesSequence []coord.Coord, whiteStarts bool) []*image.Paletted {
-	game := othellogame.New(&tgbotapi.User{}, &tgbotapi.User{})
-	game.SetTurn(whiteStarts)
+func drawOverlay(dst draw.Image, text string) {
+	drawTextCentered(dst, text, overlayOrigin, 2, overlayColor)
+}
+
+func overlayText(moveNumber, whiteDisks, blackDisks int) string {
+	return fmt.Sprintf("Move %d   White %d : %d Black", moveNumber, whiteDisks, blackDisks)
+}
 
-	res := make([]*image.Paletted, 0, len(movesSequence))
-	res = append(res, getGameFrame(game))
-	for _, move := range movesSequence {
-		game.PlaceDiskUnchecked(move)
-		res = append(res, getGameFrame(game))
+func flippedDisks(before, after [][]cell.Cell) []coord.Coord {
+	res := make([]coord.Coord, 0)
+	for y := range before {
+		for x := range before[y] {
+			if before[y][x] != cell.Empty && before[y][x] != after[y][x] {
+				res = append(res, coord.New(x, y))
+			}
+		}
 	}
 	return res
 }
 
-func getGameFrame(game *othellogame.Game) *image.Paletted {
-	const (
-		x0 = 77
-		y0 = 121
-	)
-	const (
-		diskLength = 39
-		cellLength = 44
-	)
-
-	res := cloneImage(boardImage)
-	board := game.Board()
+func copyBoard(board [][]cell.Cell) [][]cell.Cell {
+	res := make([][]cell.Cell, len(board))
 	for i := range board {
-		for j := range board[i] {
-			if board[i][j] == cell.Empty {
-				continue
-			}
+		res[i] = make([]cell.Cell, len(board[i]))
+		copy(res[i], board[i])
+	}
+	return res
+}
 
-			x := x0 + j*cellLength
-			y := y0 + i*cellLength
-			draw.Draw(
-				res,
-				image.Rect(x, y, x+diskLength, y+diskLength),
-				cellToImage[board[i][j]],
-				image.Point{},
-				draw.Over,
-			)
-		}
+func withoutCoordinates(board image.Image) image.Image {
+	res := image.NewRGBA(board.Bounds())
+	draw.Draw(res, res.Bounds(), board, image.Point{}, draw.Src)
+	background := image.NewUniform(board.At(0, 0))
+	for _, rect := range coordinatesLabelsBounds {
+		draw.Draw(res, rect, background, image.Point{}, draw.Src)
 	}
 	return res
 }
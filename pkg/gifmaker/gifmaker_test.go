@@ -0,0 +1,326 @@
+package gifmaker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	"golang.org/x/image/webp"
+)
+
+var update = flag.Bool("update", false, "update the golden images in testdata")
+
+var testMoves = []coord.Coord{
+	coord.New(2, 3),
+	coord.New(2, 2),
+	coord.New(3, 2),
+	coord.New(4, 2),
+	coord.New(5, 4),
+	coord.New(2, 4),
+}
+
+func TestGoldenFrames(t *testing.T) {
+	opts := DefaultOptions()
+	frames := getGameFrames(testMoves, false, opts)
+	transitions := getGameFrames(testMoves[:1], false, opts)
+
+	noExtras := opts
+	noExtras.MarkLastMove = false
+	noExtras.HighlightFlips = false
+	noExtras.ShowOverlay = false
+	noExtras.ShowCoordinates = false
+	plain := getGameFrames(testMoves, false, noExtras)
+
+	withHoles := opts
+	start, err := othellogame.ParsePosition("x------x/-x----x-/--------/---wb---/---bw---/--------/-x----x-/x------x b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withHoles.Start = start
+	holes := getGameFrames(testMoves, false, withHoles)
+
+	shapes := opts
+	shapes.Theme = theme.Shapes
+	halloween := opts
+	halloween.Theme = theme.Halloween
+
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"start", frames[0].rgba(opts)},
+		{"after-moves", frames[len(frames)-1].rgba(opts)},
+		{"after-moves-paletted", frames[len(frames)-1].paletted(opts)},
+		{"flipping", transitions[2].rgba(opts)},
+		{"plain", plain[len(plain)-1].rgba(noExtras)},
+		{"holes", holes[len(holes)-1].rgba(withHoles)},
+		{"holes-paletted", holes[len(holes)-1].paletted(withHoles)},
+		{"theme-shapes", frames[len(frames)-1].rgba(shapes)},
+		{"theme-halloween-paletted", frames[len(frames)-1].paletted(halloween)},
+	}
+	for _, size := range []int{6, 10, 12} {
+		opts := opts
+		opts.BoardSize = size
+		// the same moves relative to the starting position in the center
+		shift := (size - 8) / 2
+		moves := make([]coord.Coord, len(testMoves))
+		for i, move := range testMoves {
+			moves[i] = coord.New(move.X+shift, move.Y+shift)
+		}
+		frames := getGameFrames(moves, false, opts)
+		tests = append(tests, struct {
+			name string
+			img  image.Image
+		}{fmt.Sprintf("size-%d", size), frames[len(frames)-1].rgba(opts)})
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkGolden(t, test.name, test.img)
+		})
+	}
+}
+
+func checkGolden(t *testing.T, name string, img image.Image) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".png")
+	if *update {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("%v; run the tests with -update to create the golden images", err)
+	}
+	defer file.Close()
+	want, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x, y, ok := samePixels(img, want); !ok {
+		t.Errorf("%s differs from the golden image at (%d, %d)", name, x, y)
+	}
+}
+
+func samePixels(a, b image.Image) (x, y int, ok bool) {
+	if a.Bounds() != b.Bounds() {
+		return a.Bounds().Min.X, a.Bounds().Min.Y, false
+	}
+	for y := a.Bounds().Min.Y; y < a.Bounds().Max.Y; y++ {
+		for x := a.Bounds().Min.X; x < a.Bounds().Max.X; x++ {
+			if !sameColor(a.At(x, y), b.At(x, y)) {
+				return x, y, false
+			}
+		}
+	}
+	return 0, 0, true
+}
+
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+func TestVP8LRoundTrip(t *testing.T) {
+	board := getGameFrames(testMoves, false, DefaultOptions())[0].rgba(DefaultOptions())
+
+	noise := image.NewRGBA(image.Rect(0, 0, 120, 80))
+	rnd := rand.New(rand.NewSource(1))
+	for i := range noise.Pix {
+		noise.Pix[i] = byte(rnd.Intn(256))
+		if i%4 == 3 {
+			noise.Pix[i] = 0xff
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  *image.RGBA
+	}{
+		{"board", board},
+		{"part of board", board.SubImage(image.Rect(75, 119, 160, 170)).(*image.RGBA)},
+		{"noise", noise},
+		{"single pixel", image.NewRGBA(image.Rect(0, 0, 1, 1))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var chunk bytes.Buffer
+			writeRIFFChunk(&chunk, "VP8L", encodeVP8L(test.img))
+
+			var file bytes.Buffer
+			file.WriteString("RIFF")
+			binary.Write(&file, binary.LittleEndian, uint32(chunk.Len()+4))
+			file.WriteString("WEBP")
+			file.Write(chunk.Bytes())
+
+			decoded, err := webp.Decode(&file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bounds := test.img.Bounds()
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					if !sameColor(decoded.At(x, y), test.img.At(bounds.Min.X+x, bounds.Min.Y+y)) {
+						t.Fatalf("decoded image differs at (%d, %d)", x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFormats(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Speed = SpeedFast
+
+	t.Run("gif", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Make(&buf, testMoves, false, opts); err != nil {
+			t.Fatal(err)
+		}
+		g, err := gif.DecodeAll(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := len(getGameFrames(testMoves, false, opts)); len(g.Image) != want {
+			t.Errorf("got %d frames, want %d", len(g.Image), want)
+		}
+	})
+
+	t.Run("apng", func(t *testing.T) {
+		opts := opts
+		opts.Format = FormatAPNG
+		var buf bytes.Buffer
+		if err := Make(&buf, testMoves, false, opts); err != nil {
+			t.Fatal(err)
+		}
+		// decoders without APNG support show the first frame
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := getGameFrames(testMoves, false, opts)[0].rgba(opts)
+		if x, y, ok := samePixels(img, first); !ok {
+			t.Errorf("default image differs from the first frame at (%d, %d)", x, y)
+		}
+	})
+
+	t.Run("webp", func(t *testing.T) {
+		opts := opts
+		opts.Format = FormatWebP
+		var buf bytes.Buffer
+		if err := Make(&buf, testMoves, false, opts); err != nil {
+			t.Fatal(err)
+		}
+		b := buf.Bytes()
+		if string(b[:4]) != "RIFF" || string(b[8:12]) != "WEBP" {
+			t.Fatal("missing the RIFF header")
+		}
+		if size := binary.LittleEndian.Uint32(b[4:8]); int(size) != len(b)-8 {
+			t.Errorf("RIFF size is %d, want %d", size, len(b)-8)
+		}
+	})
+
+	t.Run("mp4", func(t *testing.T) {
+		if !FormatMP4.Available() {
+			t.Skip("ffmpeg is not installed")
+		}
+		opts := opts
+		opts.Format = FormatMP4
+		var buf bytes.Buffer
+		if err := Make(&buf, testMoves, false, opts); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() == 0 {
+			t.Error("empty video")
+		}
+	})
+	t.Run("transcript with passes", func(t *testing.T) {
+		opts := opts
+		opts.Format = FormatText
+		opts.Start, _ = othellogame.ParsePosition("-wb-----/--------/--------/--------/--------/--------/--------/-----wwb b")
+		recorded := []coord.Coord{coord.New(0, 0), othellogame.Pass, coord.New(4, 7)}
+		// stored before passes were recorded
+		inferred := []coord.Coord{coord.New(0, 0), coord.New(4, 7)}
+
+		var got, old bytes.Buffer
+		if err := Make(&got, recorded, false, opts); err != nil {
+			t.Fatal(err)
+		}
+		if err := Make(&old, inferred, false, opts); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(got.String(), "1. a1 --\n2. e8\n") {
+			t.Errorf("transcript is %q", got.String())
+		}
+		if got.String() != old.String() {
+			t.Errorf("transcript of the inferred passes is %q, want %q", old.String(), got.String())
+		}
+	})
+}
+
+func TestParseOptions(t *testing.T) {
+	for _, speed := range Speeds {
+		if got, ok := ParseSpeed(string(speed)); !ok || got != speed {
+			t.Errorf("ParseSpeed(%q) = %q, %v", speed, got, ok)
+		}
+	}
+	for _, f := range Formats {
+		if got, ok := ParseFormat(string(f)); !ok || got != f {
+			t.Errorf("ParseFormat(%q) = %q, %v", f, got, ok)
+		}
+	}
+	for _, s := range []string{"", "$set", "fast.gif", "GIF"} {
+		if _, ok := ParseSpeed(s); ok {
+			t.Errorf("ParseSpeed(%q) succeeded", s)
+		}
+		if _, ok := ParseFormat(s); ok {
+			t.Errorf("ParseFormat(%q) succeeded", s)
+		}
+	}
+}
+
+func TestDrawUniformClipped(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	mask := image.NewAlpha(image.Rect(0, 0, 4, 1))
+	// only the third pixel of the mask is opaque
+	mask.SetAlpha(2, 0, color.Alpha{A: 0xff})
+
+	paletted := image.NewPaletted(image.Rect(0, 0, 4, 1), palette)
+	rgba := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	r := mask.Bounds().Add(image.Pt(-1, 0))
+	drawUniform(paletted, r, image.NewUniform(color.White), mask, image.Point{})
+	drawUniform(rgba, r, image.NewUniform(color.White), mask, image.Point{})
+
+	for x := 0; x < 4; x++ {
+		want := x == 1
+		if got := paletted.ColorIndexAt(x, 0) == 1; got != want {
+			t.Errorf("paletted pixel %d is drawn: %v, want %v", x, got, want)
+		}
+		if _, _, _, a := rgba.At(x, 0).RGBA(); (a == 0xffff) != want {
+			t.Errorf("RGBA pixel %d is drawn: %v, want %v", x, a == 0xffff, want)
+		}
+	}
+}
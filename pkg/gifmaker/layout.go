@@ -0,0 +1,202 @@
+package gifmaker
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"sync"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	xdraw "golang.org/x/image/draw"
+)
+
+// The board image only has a grid of 8×8 cells, so the grids of other sizes
+// are drawn over it in the same area.
+var (
+	gridOrigin     = image.Pt(74, 118)
+	gridLength     = 352
+	gridColor      = image.NewUniform(color.Black)
+	labelsColor    = image.NewUniform(color.White)
+	holeColor      = image.NewUniform(color.RGBA{R: 40, G: 40, B: 40, A: 255})
+	boardDiskRatio = 39.0 / 44
+)
+
+// layout is where the cells of a board of some size are on the replay images.
+type layout struct {
+	size       int
+	theme      theme.Theme
+	origin     image.Point
+	cellLength int
+	diskLength int
+
+	board                      image.Image
+	boardNoCoordinates         image.Image
+	boardPaletted              *image.Paletted
+	boardNoCoordinatesPaletted *image.Paletted
+	disks                      map[cell.Cell]image.Image
+}
+
+type layoutKey struct {
+	size  int
+	theme theme.Theme
+}
+
+var (
+	layouts      = make(map[layoutKey]*layout)
+	layoutsMutex sync.Mutex
+)
+
+// layoutOf returns the layout of boards of the size with the disks of t,
+// where the zero theme is the classic one.
+func layoutOf(size int, t theme.Theme) *layout {
+	layoutsMutex.Lock()
+	defer layoutsMutex.Unlock()
+
+	t, _ = theme.Parse(string(t))
+	return cachedLayout(size, t)
+}
+
+func cachedLayout(size int, t theme.Theme) *layout {
+	key := layoutKey{size, t}
+	if l, ok := layouts[key]; ok {
+		return l
+	}
+
+	if t != theme.Classic {
+		// the boards are the same in all themes
+		l := *cachedLayout(size, theme.Classic)
+		l.theme = t
+		l.disks = withHole(themeDiskImages(t, l.diskLength), l.diskLength)
+		layouts[key] = &l
+		return &l
+	}
+
+	var l *layout
+	if size == othellogame.DefaultBoardSize {
+		l = &layout{
+			size:               size,
+			theme:              theme.Classic,
+			origin:             gridOrigin,
+			cellLength:         gridLength / size,
+			diskLength:         cellToImage[cell.White].Bounds().Dx(),
+			board:              boardPNG,
+			boardNoCoordinates: boardPNGNoCoordinates,
+			disks:              cellToImage,
+		}
+	} else {
+		l = newDrawnLayout(size)
+	}
+	l.disks = withHole(l.disks, l.diskLength)
+	l.boardPaletted = imageToPaletted(l.board)
+	l.boardNoCoordinatesPaletted = imageToPaletted(l.boardNoCoordinates)
+	layouts[key] = l
+	return l
+}
+
+func newDrawnLayout(size int) *layout {
+	cellLength := gridLength / size
+	margin := (gridLength - cellLength*size) / 2
+	l := &layout{
+		size:       size,
+		theme:      theme.Classic,
+		origin:     gridOrigin.Add(image.Pt(margin, margin)),
+		cellLength: cellLength,
+		diskLength: int(float64(cellLength)*boardDiskRatio + 0.5),
+		disks:      make(map[cell.Cell]image.Image),
+	}
+
+	for c, img := range cellToImage {
+		disk := image.NewRGBA(image.Rect(0, 0, l.diskLength, l.diskLength))
+		xdraw.CatmullRom.Scale(disk, disk.Bounds(), img, img.Bounds(), draw.Src, nil)
+		l.disks[c] = disk
+	}
+
+	noCoordinates := image.NewRGBA(boardPNG.Bounds())
+	draw.Draw(noCoordinates, noCoordinates.Bounds(), boardPNGNoCoordinates, image.Point{}, draw.Src)
+	background := image.NewUniform(boardPNG.At(0, 0))
+	gridBounds := image.Rectangle{
+		Min: gridOrigin,
+		Max: gridOrigin.Add(image.Pt(gridLength+1, gridLength+1)),
+	}
+	draw.Draw(noCoordinates, gridBounds, background, image.Point{}, draw.Src)
+	for i := 0; i <= size; i++ {
+		offset := i * cellLength
+		vertical := image.Rect(0, 0, 1, cellLength*size+1).Add(l.origin.Add(image.Pt(offset, 0)))
+		horizontal := image.Rect(0, 0, cellLength*size+1, 1).Add(l.origin.Add(image.Pt(0, offset)))
+		draw.Draw(noCoordinates, vertical, gridColor, image.Point{}, draw.Src)
+		draw.Draw(noCoordinates, horizontal, gridColor, image.Point{}, draw.Src)
+	}
+	l.boardNoCoordinates = noCoordinates
+
+	withCoordinates := image.NewRGBA(noCoordinates.Bounds())
+	draw.Draw(withCoordinates, withCoordinates.Bounds(), noCoordinates, image.Point{}, draw.Src)
+	files, ranks := coordinatesLabelsBounds[0], coordinatesLabelsBounds[1]
+	for i := 0; i < size; i++ {
+		center := l.origin.X + i*cellLength + cellLength/2
+		top := image.Pt(center, files.Min.Y+3)
+		drawTextCentered(withCoordinates, string(rune('a'+i)), top, 2, labelsColor)
+
+		middle := l.origin.Y + i*cellLength + cellLength/2
+		top = image.Pt((ranks.Min.X+ranks.Max.X)/2, middle-13)
+		drawTextCentered(withCoordinates, strconv.Itoa(i+1), top, 2, labelsColor)
+	}
+	l.board = withCoordinates
+
+	return l
+}
+
+// withHole returns a copy of disks with the image of holes,
+// a dark square as large as the disks.
+func withHole(disks map[cell.Cell]image.Image, length int) map[cell.Cell]image.Image {
+	res := make(map[cell.Cell]image.Image, len(disks)+1)
+	for c, img := range disks {
+		res[c] = img
+	}
+	hole := image.NewRGBA(image.Rect(0, 0, length, length))
+	draw.Draw(hole, hole.Bounds(), holeColor, image.Point{}, draw.Src)
+	res[cell.Hole] = hole
+	return res
+}
+
+func (l *layout) diskRect(where coord.Coord) image.Rectangle {
+	padding := (l.cellLength + 1 - l.diskLength) / 2
+	x := l.origin.X + where.X*l.cellLength + padding
+	y := l.origin.Y + where.Y*l.cellLength + padding
+	return image.Rect(x, y, x+l.diskLength, y+l.diskLength)
+}
+
+func (l *layout) diskCenter(where coord.Coord) image.Point {
+	rect := l.diskRect(where)
+	return image.Pt((rect.Min.X+rect.Max.X)/2, (rect.Min.Y+rect.Max.Y)/2)
+}
+
+// markerRadius scales the radius of a marker drawn on 8×8 boards
+// to the cells of l.
+func (l *layout) markerRadius(radius int) int {
+	res := radius * l.cellLength / (gridLength / othellogame.DefaultBoardSize)
+	if res < 2 {
+		return 2
+	}
+	return res
+}
+
+func (l *layout) drawDisks(dst draw.Image, board [][]cell.Cell) {
+	for i := range board {
+		for j := range board[i] {
+			if board[i][j] == cell.Empty {
+				continue
+			}
+			draw.Draw(
+				dst,
+				l.diskRect(coord.New(j, i)),
+				l.disks[board[i][j]],
+				image.Point{},
+				draw.Over,
+			)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package gifmaker
+
+import (
+	"os/exec"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
+)
+
+type Speed string
+
+const (
+	SpeedSlow   Speed = "slow"
+	SpeedNormal Speed = "normal"
+	SpeedFast   Speed = "fast"
+)
+
+var Speeds = [...]Speed{SpeedSlow, SpeedNormal, SpeedFast}
+
+func ParseSpeed(s string) (Speed, bool) {
+	for _, speed := range Speeds {
+		if string(speed) == s {
+			return speed, true
+		}
+	}
+	return SpeedNormal, false
+}
+
+// Delay returns the delay of each frame of a replay in 100ths of a second.
+func (s Speed) Delay() int {
+	switch s {
+	case SpeedSlow:
+		return 300
+	case SpeedFast:
+		return 80
+	default:
+		return 200
+	}
+}
+
+func (s Speed) Label() string {
+	switch s {
+	case SpeedSlow:
+		return "🐢 Slow"
+	case SpeedFast:
+		return "⚡️ Fast"
+	default:
+		return "▶️ Normal"
+	}
+}
+
+type Format string
+
+const (
+	FormatGIF  Format = "gif"
+	FormatAPNG Format = "apng"
+	FormatWebP Format = "webp"
+	FormatMP4  Format = "mp4"
+	FormatText Format = "txt"
+)
+
+var Formats = [...]Format{FormatGIF, FormatAPNG, FormatWebP, FormatMP4, FormatText}
+
+func ParseFormat(s string) (Format, bool) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, true
+		}
+	}
+	return FormatGIF, false
+}
+
+func (f Format) Extension() string {
+	if f == FormatAPNG {
+		return "png"
+	}
+	return string(f)
+}
+
+func (f Format) Label() string {
+	switch f {
+	case FormatAPNG:
+		return "APNG"
+	case FormatWebP:
+		return "WebP"
+	case FormatMP4:
+		return "MP4"
+	case FormatText:
+		return "📝 Moves"
+	default:
+		return "GIF"
+	}
+}
+
+// Available reports whether replays can be encoded in f;
+// MP4 replays need ffmpeg to be installed.
+func (f Format) Available() bool {
+	if f == FormatMP4 {
+		_, err := exec.LookPath("ffmpeg")
+		return err == nil
+	}
+	return true
+}
+
+type Options struct {
+	Speed  Speed
+	Format Format
+
+	// BoardSize is the number of rows and columns of the board of the game,
+	// or 0 for boards of othellogame.DefaultBoardSize.
+	BoardSize int
+
+	// Start is the position the game started from,
+	// or nil for the standard one.
+	Start *othellogame.Position
+
+	// Theme is the theme of the disks, or empty for the classic one.
+	Theme theme.Theme
+
+	// FinalFrameDelay is how long the final position is held
+	// in 100ths of a second.
+	FinalFrameDelay int
+
+	MarkLastMove    bool
+	HighlightFlips  bool
+	AnimateFlips    bool
+	ShowOverlay     bool
+	ShowCoordinates bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Speed:           SpeedNormal,
+		Format:          FormatGIF,
+		FinalFrameDelay: 500,
+		MarkLastMove:    true,
+		HighlightFlips:  true,
+		AnimateFlips:    true,
+		ShowOverlay:     true,
+		ShowCoordinates: true,
+	}
+}
+
+func (o Options) boardSize() int {
+	if o.Start != nil {
+		return o.Start.Size()
+	}
+	if o.BoardSize == 0 {
+		return othellogame.DefaultBoardSize
+	}
+	return o.BoardSize
+}
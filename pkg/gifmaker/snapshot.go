@@ -0,0 +1,47 @@
+package gifmaker
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
+)
+
+var (
+	lastMoveColor = image.NewUniform(color.RGBA{R: 220, G: 40, B: 40, A: 255})
+	hintColor     = image.NewUniform(color.RGBA{R: 250, G: 240, B: 120, A: 255})
+)
+
+// Snapshot renders the current position of game as a PNG image with
+// the last placed disk and the hint marked, and the selected move with
+// its flips if showSelection is true, with the disks of t.
+func Snapshot(game *othellogame.Game, t theme.Theme, showSelection bool) ([]byte, error) {
+	l := layoutOf(game.Size(), t)
+	img := image.NewRGBA(l.board.Bounds())
+	draw.Draw(img, img.Bounds(), l.board, image.Point{}, draw.Src)
+	l.drawDisks(img, game.Board())
+
+	if lastMove, ok := game.LastMove(); ok {
+		center := l.diskCenter(lastMove)
+		drawCircle(img, center, l.markerRadius(6), lastMoveColor)
+	}
+	if hint, ok := game.Hint(); ok {
+		drawCircle(img, l.diskCenter(hint), l.markerRadius(10), hintColor)
+	}
+	if selected, ok := game.Selected(); ok && showSelection {
+		drawCircle(img, l.diskCenter(selected), l.markerRadius(10), flippedDiskColor)
+		for _, c := range game.SelectedFlips() {
+			drawCircle(img, l.diskCenter(c), l.markerRadius(4), flippedDiskColor)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
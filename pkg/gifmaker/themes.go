@@ -0,0 +1,91 @@
+package gifmaker
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
+)
+
+// The disks of the themes other than the classic one are drawn rather than
+// read from the resources, in colors matching the emojis of the themes.
+
+type diskMark int
+
+const (
+	noMark diskMark = iota
+	crossMark
+	ringMark
+)
+
+type themeDisk struct {
+	fill, border color.NRGBA
+	mark         diskMark
+	markColor    color.NRGBA
+}
+
+var themeDisks = map[theme.Theme]map[cell.Cell]themeDisk{
+	theme.HighContrast: {
+		cell.Black: {fill: rgb(0, 70, 210), border: rgb(0, 0, 0)},
+		cell.White: {fill: rgb(255, 215, 0), border: rgb(0, 0, 0)},
+	},
+	theme.Shapes: {
+		cell.Black: {fill: rgb(30, 30, 30), border: rgb(0, 0, 0), mark: crossMark, markColor: rgb(240, 240, 240)},
+		cell.White: {fill: rgb(240, 240, 240), border: rgb(0, 0, 0), mark: ringMark, markColor: rgb(30, 30, 30)},
+	},
+	theme.Nowruz: {
+		cell.Black: {fill: rgb(235, 110, 20), border: rgb(120, 50, 0)},
+		cell.White: {fill: rgb(245, 235, 210), border: rgb(160, 140, 100)},
+	},
+	theme.Halloween: {
+		cell.Black: {fill: rgb(70, 20, 100), border: rgb(20, 0, 30)},
+		cell.White: {fill: rgb(255, 120, 0), border: rgb(90, 40, 0)},
+	},
+}
+
+func rgb(r, g, b uint8) color.NRGBA {
+	return color.NRGBA{R: r, G: g, B: b, A: 255}
+}
+
+// themeDiskImages returns the images of the disks of t with the length.
+func themeDiskImages(t theme.Theme, length int) map[cell.Cell]image.Image {
+	res := make(map[cell.Cell]image.Image, 2)
+	for c, disk := range themeDisks[t] {
+		res[c] = disk.image(length)
+	}
+	return res
+}
+
+func (d themeDisk) image(length int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, length, length))
+	radius := float64(length) / 2
+	border := math.Max(1, radius/8)
+	markWidth := radius / 8
+	for y := 0; y < length; y++ {
+		for x := 0; x < length; x++ {
+			dx, dy := float64(x)+0.5-radius, float64(y)+0.5-radius
+			distance := math.Hypot(dx, dy)
+			// antialiased edge
+			alpha := math.Min(1, radius-distance)
+			if alpha <= 0 {
+				continue
+			}
+
+			c := d.fill
+			switch {
+			case distance > radius-border:
+				c = d.border
+			case d.mark == crossMark && distance < radius*0.6 &&
+				(math.Abs(dx-dy) < markWidth*math.Sqrt2 || math.Abs(dx+dy) < markWidth*math.Sqrt2):
+				c = d.markColor
+			case d.mark == ringMark && distance > radius*0.3 && distance < radius*0.3+2*markWidth:
+				c = d.markColor
+			}
+			c.A = uint8(255 * alpha)
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
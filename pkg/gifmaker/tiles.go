@@ -0,0 +1,57 @@
+package gifmaker
+
+import (
+	"image"
+	"sync"
+
+	"github.com/ArminGh02/othello-bot/pkg/theme"
+)
+
+// Drawing onto a paletted image looks up the nearest palette color of every
+// pixel, which is too slow for the hundreds of frames of an animated replay.
+// So each disk is drawn once per cell, color and scale into a tile that
+// frames copy their pixels from.
+
+type tileKey struct {
+	base  *image.Paletted
+	theme theme.Theme
+	disk  sprite
+}
+
+var (
+	tiles      = make(map[tileKey]*image.Paletted)
+	tilesMutex sync.Mutex
+)
+
+func diskTile(l *layout, base *image.Paletted, disk sprite) *image.Paletted {
+	key := tileKey{base, l.theme, disk}
+
+	tilesMutex.Lock()
+	defer tilesMutex.Unlock()
+
+	if tile, ok := tiles[key]; ok {
+		return tile
+	}
+
+	rect := l.diskRect(disk.where)
+	tile := image.NewPaletted(rect, base.Palette)
+	copyPaletted(tile, base, rect)
+	drawDiskScaled(tile, l, disk)
+	tiles[key] = tile
+	return tile
+}
+
+func drawTile(dst, tile *image.Paletted) {
+	copyPaletted(dst, tile, tile.Bounds())
+}
+
+// copyPaletted copies the pixels of r from src to dst,
+// which must have the same palette.
+func copyPaletted(dst, src *image.Paletted, r image.Rectangle) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		copy(
+			dst.Pix[dst.PixOffset(r.Min.X, y):dst.PixOffset(r.Max.X, y)],
+			src.Pix[src.PixOffset(r.Min.X, y):src.PixOffset(r.Max.X, y)],
+		)
+	}
+}
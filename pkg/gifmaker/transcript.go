@@ -0,0 +1,55 @@
+package gifmaker
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+// writeTranscript writes the moves of a game in a PGN-like notation, one
+// line per pair of moves with the first player's move first, and "--" for
+// the passes, which are inferred from the colors of the disks for the
+// sequences stored before passes were recorded.
+func writeTranscript(w io.Writer, movesSequence []coord.Coord, whiteStarts bool, opts Options) error {
+	game := newReplayGame(opts)
+	game.SetTurn(whiteStarts)
+
+	first := cell.Black
+	if whiteStarts {
+		first = cell.White
+	}
+
+	plies := make([]string, 0, len(movesSequence))
+	for _, move := range movesSequence {
+		if move == othellogame.Pass {
+			plies = append(plies, "--")
+			continue
+		}
+		game.PlaceDiskUnchecked(move)
+		color := game.Board()[move.Y][move.X]
+		if (len(plies)%2 == 0) != (color == first) {
+			plies = append(plies, "--")
+		}
+		plies = append(plies, move.String())
+	}
+
+	var b strings.Builder
+	if opts.Start != nil {
+		start := game.StartPosition()
+		fmt.Fprintf(&b, "Start: %s\n", start)
+	}
+	for i := 0; i < len(plies); i += 2 {
+		fmt.Fprintf(&b, "%d. %s", i/2+1, plies[i])
+		if i+1 < len(plies) {
+			fmt.Fprintf(&b, " %s", plies[i+1])
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "White %d - %d Black\n", game.WhiteDisks(), game.BlackDisks())
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,137 @@
+package gifmaker
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	// flipFrames is the number of frames each disk takes to flip; the first half
+	// squashes the old color and the second half stretches the new one.
+	flipFrames           = 4
+	transitionFrameDelay = 4
+)
+
+// getTransitionFrames returns the frames between the position before a move
+// and the position after it, in which the placed disk grows and the flipped
+// disks turn over, rippling outward from the placed disk.
+func getTransitionFrames(
+	before [][]cell.Cell,
+	placed coord.Coord,
+	color cell.Cell,
+	flipped []coord.Coord,
+	moveNumber int,
+	opts Options,
+) []frame {
+	maxDistance := 0
+	for _, c := range flipped {
+		if d := distance(placed, c); d > maxDistance {
+			maxDistance = d
+		}
+	}
+
+	overlay := ""
+	if opts.ShowOverlay {
+		whiteDisks, blackDisks := countDisks(before)
+		overlay = overlayText(moveNumber, whiteDisks, blackDisks)
+	}
+
+	stable := copyBoard(before)
+	for _, c := range flipped {
+		stable[c.Y][c.X] = cell.Empty
+	}
+
+	steps := maxDistance + flipFrames - 1
+	if steps < flipFrames {
+		steps = flipFrames
+	}
+	res := make([]frame, 0, steps)
+	for step := 0; step < steps; step++ {
+		disks := boardSprites(stable)
+
+		if step < flipFrames {
+			scale := float64(step+1) / (flipFrames + 1)
+			disks = append(disks, sprite{placed, color, scale, scale})
+		} else {
+			disks = append(disks, sprite{placed, color, 1, 1})
+		}
+
+		for _, c := range flipped {
+			k := step - (distance(placed, c) - 1)
+			switch {
+			case k < 0:
+				disks = append(disks, sprite{c, color.Reversed(), 1, 1})
+			case k < flipFrames/2:
+				scale := 1 - float64(k+1)/(flipFrames/2+1)
+				disks = append(disks, sprite{c, color.Reversed(), scale, 1})
+			case k < flipFrames:
+				scale := float64(k-flipFrames/2+1) / (flipFrames/2 + 1)
+				disks = append(disks, sprite{c, color, scale, 1})
+			default:
+				disks = append(disks, sprite{c, color, 1, 1})
+			}
+		}
+
+		res = append(res, frame{
+			disks:   disks,
+			overlay: overlay,
+			delay:   transitionFrameDelay,
+		})
+	}
+	return res
+}
+
+func drawDiskScaled(dst draw.Image, l *layout, disk sprite) {
+	src := l.disks[disk.color]
+	if disk.scaleX == 1 && disk.scaleY == 1 {
+		draw.Draw(dst, l.diskRect(disk.where), src, image.Point{}, draw.Over)
+		return
+	}
+
+	rect := l.diskRect(disk.where)
+	center := l.diskCenter(disk.where)
+	width := int(float64(rect.Dx())*disk.scaleX + 0.5)
+	height := int(float64(rect.Dy())*disk.scaleY + 0.5)
+	if width == 0 || height == 0 {
+		return
+	}
+
+	min := image.Pt(center.X-width/2, center.Y-height/2)
+	target := image.Rectangle{Min: min, Max: min.Add(image.Pt(width, height))}
+	xdraw.ApproxBiLinear.Scale(dst, target, src, src.Bounds(), draw.Over, nil)
+}
+
+// distance returns the number of steps between two cells
+// on the same row, column or diagonal.
+func distance(a, b coord.Coord) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func countDisks(board [][]cell.Cell) (white, black int) {
+	for _, row := range board {
+		for _, c := range row {
+			switch c {
+			case cell.White:
+				white++
+			case cell.Black:
+				black++
+			}
+		}
+	}
+	return white, black
+}
@@ -2,18 +2,25 @@ package gifmaker
 
 import (
 	"image"
+	"image/color"
 	"image/color/palette"
 	"image/draw"
 	"image/png"
 	"log"
-	"os"
+
+	"github.com/ArminGh02/othello-bot/resources"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
 )
 
 func readPNG(filename string) image.Image {
-	f, err := os.Open(filename)
+	f, err := resources.FS.Open(filename)
 	if err != nil {
 		log.Panicln(err)
 	}
+	defer f.Close()
 
 	img, err := png.Decode(f)
 	if err != nil {
@@ -22,8 +29,20 @@ func readPNG(filename string) image.Image {
 	return img
 }
 
+// transparentIndex replaces a barely used dark blue of the Plan9 palette
+// in replayPalette, for the pixels of frames that are unchanged since
+// the previous frame.
+const transparentIndex = 1
+
+var replayPalette = func() color.Palette {
+	res := make(color.Palette, len(palette.Plan9))
+	copy(res, palette.Plan9)
+	res[transparentIndex] = color.Transparent
+	return res
+}()
+
 func imageToPaletted(img image.Image) *image.Paletted {
-	res := image.NewPaletted(img.Bounds(), palette.Plan9)
+	res := image.NewPaletted(img.Bounds(), replayPalette)
 	draw.FloydSteinberg.Draw(res, img.Bounds(), img, image.Point{})
 	return res
 }
@@ -34,3 +53,85 @@ func cloneImage(src *image.Paletted) *image.Paletted {
 	copy(clone.Pix, src.Pix)
 	return &clone
 }
+
+type circle struct {
+	center image.Point
+	radius int
+}
+
+func (c *circle) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (c *circle) Bounds() image.Rectangle {
+	return image.Rect(
+		c.center.X-c.radius,
+		c.center.Y-c.radius,
+		c.center.X+c.radius,
+		c.center.Y+c.radius,
+	)
+}
+
+func (c *circle) At(x, y int) color.Color {
+	dx, dy := x-c.center.X, y-c.center.Y
+	if dx*dx+dy*dy < c.radius*c.radius {
+		return color.Alpha{A: 255}
+	}
+	return color.Alpha{}
+}
+
+func drawCircle(dst draw.Image, center image.Point, radius int, src *image.Uniform) {
+	mask := &circle{center: center, radius: radius}
+	drawUniform(dst, mask.Bounds(), src, mask, mask.Bounds().Min)
+}
+
+// drawUniform draws src through mask, looking up the palette
+// index of src only once if dst is paletted.
+func drawUniform(
+	dst draw.Image,
+	r image.Rectangle,
+	src *image.Uniform,
+	mask image.Image,
+	mp image.Point,
+) {
+	paletted, ok := dst.(*image.Paletted)
+	if !ok {
+		draw.DrawMask(dst, r, src, image.Point{}, mask, mp, draw.Over)
+		return
+	}
+
+	index := uint8(paletted.Palette.Index(src.C))
+	// the mask stays aligned with the unclipped rectangle, like in draw.DrawMask
+	origin := r.Min
+	r = r.Intersect(paletted.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			_, _, _, a := mask.At(mp.X+x-origin.X, mp.Y+y-origin.Y).RGBA()
+			if a >= 0x8000 {
+				paletted.SetColorIndex(x, y, index)
+			}
+		}
+	}
+}
+
+// drawTextCentered draws text horizontally centered on top, scaled up
+// by an integer factor since the only available font is a small bitmap one.
+func drawTextCentered(dst draw.Image, text string, top image.Point, scale int, src *image.Uniform) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+	height := face.Metrics().Height.Ceil()
+
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	drawer := font.Drawer{
+		Dst:  mask,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
+	}
+	drawer.DrawString(text)
+
+	min := image.Pt(top.X-width*scale/2, top.Y)
+	scaled := image.NewAlpha(image.Rect(0, 0, width*scale, height*scale))
+	xdraw.NearestNeighbor.Scale(scaled, scaled.Bounds(), mask, mask.Bounds(), draw.Src, nil)
+	drawUniform(dst, scaled.Bounds().Add(min), src, scaled, image.Point{})
+}
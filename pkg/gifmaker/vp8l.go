@@ -0,0 +1,456 @@
+package gifmaker
+
+import (
+	"container/heap"
+	"image"
+	"sort"
+)
+
+// This file implements a small encoder of the lossless WebP (VP8L) bitstream.
+// It applies the subtract-green transform, and codes runs of pixels equal to
+// the pixel to the left or above as backward references, which is enough for
+// the flat areas of board images; it uses no color cache and a single group
+// of prefix codes.
+
+const (
+	vp8lSignature         = 0x2f
+	vp8lMaxCodeLength     = 15
+	vp8lMaxCodeLengthCode = 7
+	vp8lMinMatchLength    = 3
+	vp8lMaxMatchLength    = 4096
+	vp8lGreenAlphabetSize = 256 + 24
+	vp8lDistAlphabetSize  = 40
+
+	// plane codes of the pixel above and the pixel to the left
+	vp8lDistAbove = 1
+	vp8lDistLeft  = 2
+)
+
+var vp8lCodeLengthCodeOrder = [...]int{
+	17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+}
+
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (w *bitWriter) write(value uint32, nbits uint) {
+	w.acc |= uint64(value) << w.nacc
+	w.nacc += nbits
+	for w.nacc >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nacc > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+	return w.buf
+}
+
+// vp8lToken is either a literal pixel or a backward reference.
+type vp8lToken struct {
+	argb     uint32
+	length   int
+	distCode int
+}
+
+func (t *vp8lToken) isLiteral() bool {
+	return t.length == 0
+}
+
+// encodeVP8L returns the VP8L bitstream of img.
+func encodeVP8L(img *image.RGBA) []byte {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	argb, hasAlpha := subtractGreen(img)
+
+	var w bitWriter
+	w.write(vp8lSignature, 8)
+	w.write(uint32(width-1), 14)
+	w.write(uint32(height-1), 14)
+	if hasAlpha {
+		w.write(1, 1)
+	} else {
+		w.write(0, 1)
+	}
+	w.write(0, 3) // version
+
+	w.write(1, 1) // a transform follows
+	w.write(2, 2) // subtract green
+	w.write(0, 1) // no more transforms
+
+	w.write(0, 1) // no color cache
+	w.write(0, 1) // a single group of prefix codes
+
+	tokens := vp8lTokens(argb, width)
+
+	histograms := [5][]int{
+		make([]int, vp8lGreenAlphabetSize),
+		make([]int, 256),
+		make([]int, 256),
+		make([]int, 256),
+		make([]int, vp8lDistAlphabetSize),
+	}
+	for _, t := range tokens {
+		if t.isLiteral() {
+			histograms[0][(t.argb>>8)&0xff]++
+			histograms[1][(t.argb>>16)&0xff]++
+			histograms[2][t.argb&0xff]++
+			histograms[3][t.argb>>24]++
+		} else {
+			prefix, _, _ := vp8lPrefixCode(t.length)
+			histograms[0][256+prefix]++
+			prefix, _, _ = vp8lPrefixCode(t.distCode)
+			histograms[4][prefix]++
+		}
+	}
+
+	var codes [5]prefixCode
+	for i := range codes {
+		codes[i] = writePrefixCode(&w, histograms[i])
+	}
+
+	for _, t := range tokens {
+		if t.isLiteral() {
+			codes[0].write(&w, int((t.argb>>8)&0xff))
+			codes[1].write(&w, int((t.argb>>16)&0xff))
+			codes[2].write(&w, int(t.argb&0xff))
+			codes[3].write(&w, int(t.argb>>24))
+			continue
+		}
+		prefix, extraBits, extra := vp8lPrefixCode(t.length)
+		codes[0].write(&w, 256+prefix)
+		w.write(extra, extraBits)
+		prefix, extraBits, extra = vp8lPrefixCode(t.distCode)
+		codes[4].write(&w, prefix)
+		w.write(extra, extraBits)
+	}
+	return w.bytes()
+}
+
+func subtractGreen(img *image.RGBA) (argb []uint32, hasAlpha bool) {
+	bounds := img.Bounds()
+	argb = make([]uint32, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			r, g, b, a := img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]
+			if a != 0xff {
+				hasAlpha = true
+			}
+			argb = append(argb, uint32(a)<<24|uint32(r-g)<<16|uint32(g)<<8|uint32(b-g))
+		}
+	}
+	return argb, hasAlpha
+}
+
+func vp8lTokens(argb []uint32, width int) []vp8lToken {
+	res := make([]vp8lToken, 0)
+	for i := 0; i < len(argb); {
+		left := matchLength(argb, i, 1)
+		above := 0
+		if i >= width {
+			above = matchLength(argb, i, width)
+		}
+
+		switch {
+		case above >= vp8lMinMatchLength && above >= left:
+			res = append(res, vp8lToken{length: above, distCode: vp8lDistAbove})
+			i += above
+		case left >= vp8lMinMatchLength:
+			res = append(res, vp8lToken{length: left, distCode: vp8lDistLeft})
+			i += left
+		default:
+			res = append(res, vp8lToken{argb: argb[i]})
+			i++
+		}
+	}
+	return res
+}
+
+func matchLength(argb []uint32, i, distance int) int {
+	if i < distance {
+		return 0
+	}
+	n := 0
+	for i+n < len(argb) && n < vp8lMaxMatchLength && argb[i+n] == argb[i+n-distance] {
+		n++
+	}
+	return n
+}
+
+// vp8lPrefixCode splits a length or a distance code into
+// its prefix symbol and extra bits.
+func vp8lPrefixCode(value int) (prefix int, extraBits uint, extra uint32) {
+	d := value - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	highest := 0
+	for d>>(highest+1) != 0 {
+		highest++
+	}
+	second := (d >> (highest - 1)) & 1
+	extraBits = uint(highest - 1)
+	return 2*highest + second, extraBits, uint32(d) & (1<<extraBits - 1)
+}
+
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+func (c *prefixCode) write(w *bitWriter, symbol int) {
+	w.write(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+// writePrefixCode writes a prefix code fitting histogram
+// and returns the code for writing symbols with it.
+func writePrefixCode(w *bitWriter, histogram []int) prefixCode {
+	used := make([]int, 0)
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	if len(used) <= 1 && (len(used) == 0 || used[0] < 256) {
+		// a simple code of one symbol, which takes no bits to write
+		symbol := 0
+		if len(used) == 1 {
+			symbol = used[0]
+		}
+		w.write(1, 1) // simple code
+		w.write(0, 1) // of one symbol
+		if symbol < 2 {
+			w.write(0, 1)
+			w.write(uint32(symbol), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(symbol), 8)
+		}
+		return prefixCode{
+			lengths: make([]uint8, len(histogram)),
+			codes:   make([]uint32, len(histogram)),
+		}
+	}
+
+	lengths := huffmanCodeLengths(histogram, vp8lMaxCodeLength)
+	writeCodeLengths(w, lengths)
+	return prefixCode{
+		lengths: lengths,
+		codes:   canonicalCodes(lengths),
+	}
+}
+
+func writeCodeLengths(w *bitWriter, lengths []uint8) {
+	type token struct {
+		symbol int
+		extra  uint32
+	}
+
+	tokens := make([]token, 0, len(lengths))
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, token{int(lengths[i]), 0})
+			i++
+			continue
+		}
+
+		zeros := 0
+		for i+zeros < len(lengths) && lengths[i+zeros] == 0 && zeros < 138 {
+			zeros++
+		}
+		switch {
+		case zeros >= 11:
+			tokens = append(tokens, token{18, uint32(zeros - 11)})
+		case zeros >= 3:
+			tokens = append(tokens, token{17, uint32(zeros - 3)})
+		default:
+			for j := 0; j < zeros; j++ {
+				tokens = append(tokens, token{0, 0})
+			}
+		}
+		i += zeros
+	}
+
+	histogram := make([]int, len(vp8lCodeLengthCodeOrder))
+	for _, t := range tokens {
+		histogram[t.symbol]++
+	}
+	// a code of a single symbol would be read with no bits at all,
+	// so make sure the code length code has two symbols
+	if nonzero := countNonzero(histogram); nonzero < 2 {
+		for symbol := range histogram {
+			if histogram[symbol] == 0 {
+				histogram[symbol] = 1
+				break
+			}
+		}
+	}
+	codeLengthCode := prefixCode{lengths: huffmanCodeLengths(histogram, vp8lMaxCodeLengthCode)}
+	codeLengthCode.codes = canonicalCodes(codeLengthCode.lengths)
+
+	count := 4
+	for i, symbol := range vp8lCodeLengthCodeOrder {
+		if codeLengthCode.lengths[symbol] != 0 && i+1 > count {
+			count = i + 1
+		}
+	}
+
+	w.write(0, 1) // normal code
+	w.write(uint32(count-4), 4)
+	for _, symbol := range vp8lCodeLengthCodeOrder[:count] {
+		w.write(uint32(codeLengthCode.lengths[symbol]), 3)
+	}
+	w.write(0, 1) // code lengths of all symbols follow
+
+	for _, t := range tokens {
+		codeLengthCode.write(w, t.symbol)
+		switch t.symbol {
+		case 17:
+			w.write(t.extra, 3)
+		case 18:
+			w.write(t.extra, 7)
+		}
+	}
+}
+
+func countNonzero(values []int) int {
+	res := 0
+	for _, v := range values {
+		if v != 0 {
+			res++
+		}
+	}
+	return res
+}
+
+type huffmanNode struct {
+	count  int
+	symbol int
+	left   *huffmanNode
+	right  *huffmanNode
+}
+
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int {
+	return len(h)
+}
+
+func (h huffmanHeap) Less(i, j int) bool {
+	if h[i].count == h[j].count {
+		return h[i].symbol < h[j].symbol
+	}
+	return h[i].count < h[j].count
+}
+
+func (h huffmanHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *huffmanHeap) Push(x any) {
+	*h = append(*h, x.(*huffmanNode))
+}
+
+func (h *huffmanHeap) Pop() any {
+	old := *h
+	res := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return res
+}
+
+// huffmanCodeLengths returns the code lengths of a Huffman code for
+// histogram, which must have at least two nonzero counts, flattening
+// the counts until no code is longer than maxLength.
+func huffmanCodeLengths(histogram []int, maxLength int) []uint8 {
+	counts := make([]int, len(histogram))
+	copy(counts, histogram)
+
+	for {
+		h := make(huffmanHeap, 0)
+		for symbol, count := range counts {
+			if count > 0 {
+				h = append(h, &huffmanNode{count: count, symbol: symbol})
+			}
+		}
+		heap.Init(&h)
+		for h.Len() > 1 {
+			a := heap.Pop(&h).(*huffmanNode)
+			b := heap.Pop(&h).(*huffmanNode)
+			heap.Push(&h, &huffmanNode{
+				count:  a.count + b.count,
+				symbol: len(counts) + h.Len(),
+				left:   a,
+				right:  b,
+			})
+		}
+
+		lengths := make([]uint8, len(counts))
+		tooLong := false
+		var walk func(node *huffmanNode, depth int)
+		walk = func(node *huffmanNode, depth int) {
+			if node.left == nil {
+				if depth > maxLength {
+					tooLong = true
+				}
+				lengths[node.symbol] = uint8(depth)
+				return
+			}
+			walk(node.left, depth+1)
+			walk(node.right, depth+1)
+		}
+		walk(h[0], 0)
+
+		if !tooLong {
+			return lengths
+		}
+		for symbol := range counts {
+			if counts[symbol] > 0 {
+				counts[symbol] = (counts[symbol] + 1) / 2
+			}
+		}
+	}
+}
+
+// canonicalCodes returns the codes of the canonical prefix code with
+// the given code lengths, bit-reversed since they are written LSB first.
+func canonicalCodes(lengths []uint8) []uint32 {
+	symbols := make([]int, 0, len(lengths))
+	for symbol, length := range lengths {
+		if length > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return lengths[symbols[i]] < lengths[symbols[j]]
+	})
+
+	codes := make([]uint32, len(lengths))
+	code, prevLength := uint32(0), uint8(0)
+	for i, symbol := range symbols {
+		length := lengths[symbol]
+		if i > 0 {
+			code = (code + 1) << (length - prevLength)
+		}
+		prevLength = length
+		codes[symbol] = reverseBits(code, length)
+	}
+	return codes
+}
+
+func reverseBits(code uint32, length uint8) uint32 {
+	res := uint32(0)
+	for i := uint8(0); i < length; i++ {
+		res = res<<1 | code&1
+		code >>= 1
+	}
+	return res
+}
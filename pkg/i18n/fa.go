@@ -0,0 +1,229 @@
+package i18n
+
+var persian = map[string]string{
+	// main keyboard
+	"🎮 New Game":    "🎮 بازی جدید",
+	"🏆 Scoreboard":  "🏆 جدول امتیازات",
+	"👤 Profile":     "👤 پروفایل",
+	"❓ Help":        "❓ راهنما",
+	"⚙️ Settings":   "⚙️ تنظیمات",
+	"End chat with": "پایان گفتگو با",
+
+	// help
+	"Othello is a strategy board game for two players, " +
+		"Players take turns placing disks on the board with their assigned " +
+		"color facing up. During a play, any disks of the opponent's color " +
+		"that are in a straight line and bounded by the disk just placed and " +
+		"another disk of the current player's color are turned over to the current " +
+		"player's color. played on an 8×8 uncheckered board. The objective of the game " +
+		"is to have the majority of disks turned to display one's color " +
+		"when the last playable empty square is filled.": "اتللو یک بازی فکری دونفره است که روی صفحه‌ای ۸×۸ انجام می‌شود. " +
+		"بازیکنان به نوبت مهره‌ای با رنگ خود روی صفحه می‌گذارند. " +
+		"هر مهره‌ی حریف که در یک خط راست بین مهره‌ی تازه گذاشته‌شده و " +
+		"مهره‌ی دیگری از بازیکن قرار بگیرد، به رنگ بازیکن برمی‌گردد. " +
+		"هدف بازی این است که وقتی آخرین خانه‌ی خالی پر شد، " +
+		"بیشتر مهره‌های صفحه به رنگ شما باشند.",
+	"Anti-Othello is played like Othello, " +
+		"but the player with fewer disks at the end of the game wins.": "آنتی‌اتللو مثل اتللو بازی می‌شود، " +
+		"اما بازیکنی که در پایان بازی مهره‌های کمتری دارد برنده است.",
+	"Holes are blocked cells: no disk can be placed on them, " +
+		"and they break the lines of disks to turn over.": "سوراخ‌ها خانه‌های بسته‌اند: هیچ مهره‌ای روی آن‌ها گذاشته نمی‌شود " +
+		"و خط مهره‌هایی را که برمی‌گردند قطع می‌کنند.",
+
+	// messages
+	"Hi %s\\!\n" +
+		"I am *Othello Bot*\\.\n" +
+		"Have fun playing Othello strategic board game,\n" +
+		"with your friends or opponents around the world\\!": "سلام %s\\!\n" +
+		"من *ربات اتللو* هستم\\.\n" +
+		"از بازی فکری اتللو با دوستانت\n" +
+		"یا حریفانی از سراسر دنیا لذت ببر\\!",
+	"You can't play more than one games at the same time.": "نمی‌توانید هم‌زمان بیش از یک بازی انجام دهید.",
+	"You can play Othello with opponents around the world,\n" +
+		"or play with your friends in chats!": "می‌توانید با حریفانی از سراسر دنیا اتللو بازی کنید،\n" +
+		"یا با دوستانتان در گفتگوها بازی کنید!",
+	"Sorry! %s is not recognized as a command.": "ببخشید! %s دستور شناخته‌شده‌ای نیست.",
+	"Chat ended.":              "گفتگو تمام شد.",
+	"📬 Message from %s:":       "📬 پیام از %s:",
+	"Chat with your opponent:": "گفتگو با حریف:",
+	"Your next games will show the board as an emoji keyboard.":                                     "بازی‌های بعدی شما صفحه را به شکل کیبورد ایموجی نشان می‌دهند.",
+	"Your next games with random opponents will show the board as a photo.":                         "بازی‌های بعدی شما با حریفان تصادفی صفحه را به شکل عکس نشان می‌دهند.",
+	"Your moves will be placed with a single tap.":                                                  "حرکت‌های شما با یک بار لمس انجام می‌شوند.",
+	"Your moves will be selected with the first tap and placed with a second tap on the same cell.": "حرکت‌های شما با لمس اول انتخاب و با لمس دوباره‌ی همان خانه انجام می‌شوند.",
+
+	// scoreboard and profile
+	"%d. %s %s Score: %d\n": "%d. %s %s امتیاز: %d\n",
+	"%d. %s Score: %d\n":    "%d. %s امتیاز: %d\n",
+	"🕶 Hidden player":       "🕶 بازیکن پنهان",
+	"%s's Profile:\nRank: %d\nWins: %d\nLosses: %d\nDraws: %d\nWin Percentage: %d%%": "پروفایل %s:\nرتبه: %d\nبرد: %d\nباخت: %d\nمساوی: %d\nدرصد برد: %d%%",
+	"\n\nAnti-Othello:\nWins: %d\nLosses: %d\nDraws: %d":                             "\n\nآنتی‌اتللو:\nبرد: %d\nباخت: %d\nمساوی: %d",
+	"\n\nWins as White: %d%% of %d\nWins as Black: %d%% of %d":                       "\n\nبرد با سفید: %d%% از %d\nبرد با سیاه: %d%% از %d",
+
+	// game modes and options
+	"Play with friends!":                     "بازی با دوستان!",
+	"Play with random opponents!":            "بازی با حریفان تصادفی!",
+	"%s with random opponents":               "%s با حریفان تصادفی",
+	"🔀 Random opening with random opponents": "🔀 شروع تصادفی با حریفان تصادفی",
+	"🕳 Random holes with random opponents":   "🕳 سوراخ‌های تصادفی با حریفان تصادفی",
+	"Othello":                          "اتللو",
+	"🙃 Anti-Othello":                   "🙃 آنتی‌اتللو",
+	"%s from a custom position":        "%s از یک وضعیت دلخواه",
+	"%s with a random opening":         "%s با شروع تصادفی",
+	"%s with a %d corner handicap":     "%s با آوانس %d گوشه",
+	"%s with %s":                       "%s با %s",
+	"holes on the corners":             "سوراخ در گوشه‌ها",
+	"holes next to the corners":        "سوراخ کنار گوشه‌ها",
+	"holes in the middle of the edges": "سوراخ در وسط لبه‌ها",
+	"random holes":                     "سوراخ‌های تصادفی",
+	"no holes":                         "بدون سوراخ",
+
+	// inline queries
+	"Can't play two games at the same time!": "نمی‌توانید هم‌زمان دو بازی انجام دهید!",
+	"Let's Play %s\\!":                       "بیا %s بازی کنیم\\!",
+	"%s, you play %s":                        "%s، شما %s هستید",
+	"%s plays %s":                            "%s با %s بازی می‌کند",
+	"Send down your current game":            "فرستادن بازی فعلی به پایین",
+	"%v has been moved down 🔽":               "%v به پایین منتقل شد 🔽",
+	"Join":                                   "پیوستن",
+
+	// games
+	"🙃 Anti-Othello: the player with fewer disks wins!":                               "🙃 آنتی‌اتللو: بازیکنی که مهره‌های کمتری دارد برنده است!",
+	"Turn of: %s%s\n%s%s: %d\n%s%s: %d\nDon't count your chickens before they hatch!": "نوبت: %s%s\n%s%s: %d\n%s%s: %d\nجوجه را آخر پاییز می‌شمارند!",
+	"Draw":                                "مساوی",
+	"%s%s WON! %d to %d! 🔥":               "%s%s برنده شد! %d به %d! 🔥",
+	"%s surrendered to %s!":               "%s به %s تسلیم شد!",
+	"Game ended due to inactivity of %s.": "بازی به دلیل غیرفعال بودن %s تمام شد.",
+	"🔽 Send down":                         "🔽 فرستادن به پایین",
+	"💬 Chat":                              "💬 گفتگو",
+	"Show legal moves":                    "نمایش حرکت‌های مجاز",
+	"Hide legal moves":                    "پنهان کردن حرکت‌های مجاز",
+	"🔚 End":                               "🔚 پایان",
+	"🏳️ Surrender":                        "🏳️ تسلیم",
+	"Preview flips":                       "پیش‌نمایش برگشتن‌ها",
+	"Don't preview flips":                 "بدون پیش‌نمایش برگشتن‌ها",
+	"Hint":                                "راهنمایی",
+	"🔄 Play again":                        "🔄 بازی دوباره",
+	"🎞 Game replay":                       "🎞 بازپخش بازی",
+	"🔄 Rematch":                           "🔄 بازی برگشت",
+	"Game is over!":                       "بازی تمام شد!",
+	"Tap again to place the disk.":        "برای گذاشتن مهره دوباره لمس کنید.",
+	"Disk placed!":                        "مهره گذاشته شد!",
+	"Invitation is too old!":              "این دعوت خیلی قدیمی است!",
+	"You can't play with yourself!":       "نمی‌توانید با خودتان بازی کنید!",
+	"%s is playing another game":          "%s در حال انجام بازی دیگری است",
+	"Wait until another player joins the game.":    "صبر کنید تا بازیکن دیگری به بازی بپیوندد.",
+	"Wait until another player joins the %s game.": "صبر کنید تا بازیکن دیگری به بازی %s بپیوندد.",
+	"Cancel":                             "لغو",
+	"Request was canceled.":              "درخواست لغو شد.",
+	"Toggled for you!":                   "برای شما تغییر کرد!",
+	"Hints are disabled in rated games.": "راهنمایی در بازی‌های امتیازی غیرفعال است.",
+	"You have used all your %d hints in this game.": "همه‌ی %d راهنمایی این بازی را استفاده کرده‌اید.",
+	"%s Try %s! Hints left: %d":                     "%s %s را امتحان کنید! راهنمایی باقی‌مانده: %d",
+	"You surrendered!":                              "تسلیم شدید!",
+	"You can't end the game in your turn.":          "در نوبت خودتان نمی‌توانید بازی را تمام کنید.",
+	"You can end the game if your " +
+		"opponent doesn't place a disk for %d seconds.": "اگر حریف تا %d ثانیه مهره‌ای نگذارد، می‌توانید بازی را تمام کنید.",
+	"It's not your turn!":           "نوبت شما نیست!",
+	"That cell is a hole!":          "آن خانه سوراخ است!",
+	"That cell is not empty!":       "آن خانه خالی نیست!",
+	"You can't place a disk there!": "نمی‌توانید آنجا مهره بگذارید!",
+
+	// rematches
+	"%s doesn't accept rematch requests.": "%s درخواست بازی برگشت را نمی‌پذیرد.",
+	"%s wants to rematch":                 "%s بازی برگشت می‌خواهد",
+	"Accept":                              "قبول",
+	"Reject":                              "رد",
+	"Wait for your opponent's response.":  "منتظر پاسخ حریف بمانید.",
+	"Rematch request was rejected.":       "درخواست بازی برگشت رد شد.",
+	"%s rejected the rematch request.":    "%s درخواست بازی برگشت را رد کرد.",
+
+	// replays
+	"%s White: %s | Score: %d\n%s Black: %s | Score: %d": "%s سفید: %s | امتیاز: %d\n%s سیاه: %s | امتیاز: %d",
+	"🐢 Slow":    "🐢 آهسته",
+	"▶️ Normal": "▶️ معمولی",
+	"⚡️ Fast":   "⚡️ سریع",
+	"📝 Moves":   "📝 حرکت‌ها",
+
+	// errors of the bot
+	"game is too old":                    "این بازی خیلی قدیمی است",
+	"sorry, the replay couldn't be made": "متأسفیم، بازپخش ساخته نشد",
+	"invalid game options":               "تنظیمات بازی نامعتبر است",
+	"nobody can move in that position":   "در این وضعیت هیچ‌کس نمی‌تواند حرکت کند",
+	`Type "random", "holes", "handicap 1" to "handicap 4" or a position`: `عبارت "random"، "holes"، "handicap 1" تا "handicap 4" یا یک وضعیت را بنویسید`,
+
+	// settings
+	"⚙️ Settings\n\nTap a setting to change it.": "⚙️ تنظیمات\n\nبرای تغییر هر تنظیم آن را لمس کنید.",
+	"Saved!":                   "ذخیره شد!",
+	"The language is changed.": "زبان تغییر کرد.",
+	"🌐 Language":               "🌐 زبان",
+	"Automatic":                "خودکار",
+	"📖 Opening: %s":            "📖 گشایش: %s",
+	"🎨 Theme":                  "🎨 پوسته",
+	"⚫️ Classic":               "⚫️ کلاسیک",
+	"🔵 High contrast":          "🔵 کنتراست بالا",
+	"⭕️ Shapes":                "⭕️ شکل‌ها",
+	"🐟 Nowruz":                 "🐟 نوروز",
+	"🎃 Halloween":              "🎃 هالووین",
+	"Board":                    "صفحه",
+	"Confirm moves":            "تأیید حرکت‌ها",
+	"Replay speed":             "سرعت بازپخش",
+	"Game messages":            "پیام‌های بازی",
+	"Rematch requests":         "درخواست‌های بازی برگشت",
+	"Name on scoreboard":       "نام در جدول امتیازات",
+	"Time to move":             "زمان حرکت",
+	"On":                       "روشن",
+	"Off":                      "خاموش",
+	"⌨️ Keyboard":              "⌨️ کیبورد",
+	"🖼 Photo":                  "🖼 عکس",
+	"🔔 With sound":             "🔔 با صدا",
+	"🔕 Silent":                 "🔕 بی‌صدا",
+	"Allowed":                  "مجاز",
+	"Rejected":                 "رد می‌شوند",
+	"👁 Shown":                  "👁 نمایان",
+	"🕶 Hidden":                 "🕶 پنهان",
+	"⏱ %d seconds":             "⏱ %d ثانیه",
+
+	"📊 Previous games with this position: %d": "📊 بازی‌های قبلی با این وضعیت: %d",
+	"%s has no moves — pass":                  "%s حرکتی ندارد — پاس",
+
+	"🧩 Puzzle":                        "🧩 معما",
+	"✅ Solved!":                       "✅ حل شد!",
+	"❌ Not the best move.":            "❌ بهترین حرکت نبود.",
+	"%s wins by %d disks with %s.":    "%s با اختلاف %d مهره می‌برد با %s.",
+	"🏆 Puzzle leaderboard":            "🏆 جدول معماها",
+	"%d. %s: %d solved, 🔥 %d\n":       "%d. %s: %d حل‌شده، 🔥 %d\n",
+	"The next puzzle is at midnight!": "معمای بعدی نیمه‌شب است!",
+	"Difficulty: %s":                  "سختی: %s",
+
+	"🧩 The puzzle of today isn't ready yet. Try again in a minute!":          "🧩 معمای امروز هنوز آماده نیست. یک دقیقه دیگر دوباره امتحان کنید!",
+	"You answered the puzzle of today already. The next one is at midnight!": "شما معمای امروز را جواب داده‌اید. معمای بعدی نیمه‌شب است!",
+	"🧩 Puzzle of %s\n%s to move. Find the move that wins by the most disks!": "🧩 معمای %s\nنوبت %s است. حرکتی را پیدا کنید که با بیشترین اختلاف می‌برد!",
+	"This puzzle is over. Tap 🧩 Puzzle for the puzzle of today!":             "این معما تمام شده است. برای معمای امروز 🧩 معما را بزنید!",
+	"🧩 Solved %d of %d puzzles (%d%%)\n🔥 Streak: %d":                         "🧩 %d از %d معما حل شده (%d%%)\n🔥 روزهای پیاپی: %d",
+	"\n\nPuzzles solved: %d of %d (%d%%)":                                    "\n\nمعماهای حل‌شده: %d از %d (%d%%)",
+
+	"🎓 Tutorial":                               "🎓 آموزش",
+	"🎓 Tutorial ✅":                             "🎓 آموزش ✅",
+	"🎓 Tutorial %d/%d":                         "🎓 آموزش %d/%d",
+	"🎓 Continue the tutorial (%d/%d)":          "🎓 ادامهٔ آموزش (%d/%d)",
+	"Next ▶️":                                  "بعدی ◀️",
+	"🏁 Finish":                                 "🏁 پایان",
+	"Well done!":                               "آفرین!",
+	"Three directions at once!":                "سه جهت با هم!",
+	"New to othello? Learn it in a few moves!": "با اتللو آشنا نیستید؟ با چند حرکت یادش بگیرید!",
+
+	"You play black. Place a disk so that white disks are in a line between it and another black disk. Tap one of the cells marked for you.": "شما سیاه هستید. مهره‌ای بگذارید که مهره‌های سفید در یک خط بین آن و مهرهٔ سیاه دیگری باشند. یکی از خانه‌های علامت‌خورده را بزنید.",
+	"The white disks between your disks are flipped to black!":                                                                               "مهره‌های سفید بین مهره‌های شما سیاه شدند!",
+	"A disk flips the lines it closes in all directions at once. Find the move that flips white disks in three directions.":                  "هر مهره خط‌هایی را که در همهٔ جهت‌ها می‌بندد با هم برمی‌گرداند. حرکتی را پیدا کنید که مهره‌های سفید را در سه جهت برمی‌گرداند.",
+	"That move flips disks in only one direction. Look for another one!":                                                                     "این حرکت فقط در یک جهت مهره برمی‌گرداند. دنبال حرکت دیگری بگردید!",
+	"Players who can't flip any disk must pass. Make the move that leaves white without any move.":                                           "بازیکنی که نتواند هیچ مهره‌ای برگرداند باید پاس بدهد. حرکتی کنید که سفید هیچ حرکتی نداشته باشد.",
+	"After that move white could still play. Try another one!":                                                                               "بعد از این حرکت سفید هنوز می‌تواند بازی کند. حرکت دیگری را امتحان کنید!",
+	"White has no moves and passes, so it's your turn again. When neither player can move, the game is over.":                                "سفید حرکتی ندارد و پاس می‌دهد، پس دوباره نوبت شماست. وقتی هیچ‌کدام از بازیکنان نتوانند حرکت کنند، بازی تمام می‌شود.",
+	"Disks on the corners can never be flipped, since no line goes through a corner. Take the corner!":                                       "مهره‌های گوشه هیچ‌وقت برنمی‌گردند، چون هیچ خطی از گوشه نمی‌گذرد. گوشه را بگیرید!",
+	"That isn't a corner. Look at the edges of the board!":                                                                                   "این گوشه نیست. به لبه‌های صفحه نگاه کنید!",
+	"This corner is yours for the rest of the game.":                                                                                         "این گوشه تا آخر بازی مال شماست.",
+	"Disks in a full line along the edge from your corner can never be flipped either: they are stable. Make your line on the edge longer.":  "مهره‌هایی که در یک خط پر از گوشهٔ شما در امتداد لبه هستند هم هیچ‌وقت برنمی‌گردند: آن‌ها پایدارند. خط خود را روی لبه بلندتر کنید.",
+	"That disk could be flipped back. Play next to your disks on the edge!":                                                                  "این مهره ممکن است دوباره برگردد. کنار مهره‌های خود روی لبه بازی کنید!",
+	"These four disks are stable. Collecting stable disks is how games of othello are won!":                                                  "این چهار مهره پایدارند. جمع کردن مهره‌های پایدار راه بردن اتللو است!",
+	"🎓 You completed the tutorial! Tap %s to play your first game.":                                                                          "🎓 آموزش را تمام کردید! برای اولین بازی خود %s را بزنید.",
+}
@@ -0,0 +1,116 @@
+// Package i18n translates the messages of the bot, which are written in
+// English and looked up by their English text in the catalogs of other
+// languages.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Language string
+
+const (
+	English = Language("en")
+	Persian = Language("fa")
+)
+
+var Languages = [...]Language{English, Persian}
+
+// catalogs maps languages to their translations of English messages.
+// Messages missing from a catalog are shown in English.
+var catalogs = map[Language]map[string]string{
+	Persian: persian,
+}
+
+func Parse(s string) (Language, bool) {
+	for _, l := range Languages {
+		if string(l) == s {
+			return l, true
+		}
+	}
+	return English, false
+}
+
+// FromCode returns the language of an IETF language tag like "fa-IR",
+// as Telegram reports for users, or English if it has no catalog.
+func FromCode(code string) Language {
+	base, _, _ := strings.Cut(strings.ToLower(code), "-")
+	l, _ := Parse(base)
+	return l
+}
+
+// Label is the name of the language in itself.
+func (l Language) Label() string {
+	switch l {
+	case Persian:
+		return "فارسی"
+	default:
+		return "English"
+	}
+}
+
+// IsRTL reports whether the language is written from right to left.
+func (l Language) IsRTL() bool {
+	return l == Persian
+}
+
+// T translates the message and formats it like fmt.Sprintf. Text arguments
+// of right-to-left languages are isolated, so names and moves written from
+// left to right don't scramble the words around them.
+func (l Language) T(msg string, args ...interface{}) string {
+	if translation, ok := catalogs[l][msg]; ok {
+		msg = translation
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	if l.IsRTL() {
+		isolated := make([]interface{}, len(args))
+		for i, arg := range args {
+			switch arg := arg.(type) {
+			case string:
+				isolated[i] = isolate(arg)
+			case fmt.Stringer:
+				isolated[i] = isolate(arg.String())
+			default:
+				isolated[i] = arg
+			}
+		}
+		args = isolated
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// isolate wraps s in the Unicode first strong isolate and pop directional
+// isolate marks.
+func isolate(s string) string {
+	return "\u2068" + s + "\u2069"
+}
+
+// Matches reports whether text is the message in any language, like the
+// text of a reply keyboard button sent back by its user.
+func Matches(text, msg string) bool {
+	if text == msg {
+		return true
+	}
+	for _, catalog := range catalogs {
+		if catalog[msg] == text {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPrefix reports whether text starts with the message in any language.
+func HasPrefix(text, msg string) bool {
+	if strings.HasPrefix(text, msg) {
+		return true
+	}
+	for _, catalog := range catalogs {
+		if translation, ok := catalog[msg]; ok && strings.HasPrefix(text, translation) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var verb = regexp.MustCompile(`%[-+# 0]*[0-9]*[a-zA-Z%]`)
+
+// TestCatalogVerbs checks that translations format the same arguments as
+// their English messages, in the same order.
+func TestCatalogVerbs(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for msg, translation := range catalog {
+			want := strings.Join(verb.FindAllString(msg, -1), " ")
+			got := strings.Join(verb.FindAllString(translation, -1), " ")
+			if got != want {
+				t.Errorf("%s translation of %q has verbs %q, want %q", lang, msg, got, want)
+			}
+		}
+	}
+}
+
+func TestFromCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want Language
+	}{
+		{"fa", Persian},
+		{"fa-IR", Persian},
+		{"en-US", English},
+		{"de", English},
+		{"", English},
+	}
+	for _, test := range tests {
+		if got := FromCode(test.code); got != test.want {
+			t.Errorf("FromCode(%q) = %q, want %q", test.code, got, test.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got, want := English.T("%s surrendered to %s!", "Ali", "Sara"), "Ali surrendered to Sara!"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := English.T("no translation"), "no translation"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got := Persian.T("%s surrendered to %s!", "Ali", "Sara")
+	want := "\u2068Ali\u2069 به \u2068Sara\u2069 تسلیم شد!"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	if !Matches("🎮 بازی جدید", "🎮 New Game") || !Matches("🎮 New Game", "🎮 New Game") {
+		t.Error("button texts don't match their messages")
+	}
+	if Matches("🎮 New Game", "🏆 Scoreboard") {
+		t.Error("button text matches another message")
+	}
+}
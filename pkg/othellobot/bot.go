@@ -9,21 +9,48 @@ import (
 
 	"github.com/ArminGh02/othello-bot/pkg/database"
 	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/holes"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
 	"github.com/ArminGh02/othello-bot/pkg/util"
 	"github.com/ArminGh02/othello-bot/pkg/util/coord"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	cron "github.com/robfig/cron/v3"
 )
 
-var errTooOldGame = errors.New("game is too old")
+var (
+	errTooOldGame   = errors.New("game is too old")
+	errReplayFailed = errors.New("sorry, the replay couldn't be made")
+
+	errInvalidGameOptions = errors.New("invalid game options")
+	errInvalidGameQuery   = errors.New(`Type "random", "holes", "handicap 1" to "handicap 4" or a position`)
+	errNoLegalMoves       = errors.New("nobody can move in that position")
+)
 
 type gameData struct {
 	moveSequence    []coord.Coord
 	whiteStarts     bool
+	boardSize       int
+	variant         variant.Variant
+	start           *othellogame.Position
+	handicap        int
+	randomOpening   bool
+	holes           holes.Pattern
+	whitePlayerID   int64
+	blackPlayerID   int64
 	whitePlayerName string
 	blackPlayerName string
 	whiteScore      int
 	blackScore      int
+	whiteHintsUsed  int
+	blackHintsUsed  int
+	opening         string
+	finishedAt      time.Time
+	replayFileIDs   map[string]string
+}
+
+type rematchRequest struct {
+	gameID      string
+	requestedAt time.Time
 }
 
 type Bot struct {
@@ -31,46 +58,66 @@ type Bot struct {
 	api                          *tgbotapi.BotAPI
 	db                           *database.Handler
 	scoreboard                   util.Scoreboard
-	waitingPlayer                chan *tgbotapi.User
+	optionsToWaitingPlayer       map[othellogame.Options]chan *tgbotapi.User
 	inlineMessageIDToUser        map[string]*tgbotapi.User
 	gameIDToGameData             map[string]gameData
 	gameIDToInlineMessageID      map[string]string
 	userIDToCurrentGame          map[int64]*othellogame.Game
 	userIDToLastTimeActive       map[int64]time.Time
-	userIDToMessageID            map[int64]int
+	userIDToGameMessage          map[int64]gameMessage
 	userIDToChatBuddy            map[int64]*tgbotapi.User
 	userIDToUser                 map[int64]*tgbotapi.User
-	userIDToRematchGameID        map[int64]string
+	userIDToRematchRequest       map[int64]rematchRequest
+	positionToGamesCount         map[uint64]int
 	inlineMessageIDToUserMutex   sync.Mutex
 	gameIDToMovesSequenceMutex   sync.Mutex
 	gameIDToInlineMessageIDMutex sync.Mutex
 	userIDToCurrentGameMutex     sync.Mutex
 	userIDToLastTimeActiveMutex  sync.Mutex
-	userIDToMessageIDMutex       sync.Mutex
+	userIDToGameMessageMutex     sync.Mutex
 	userIDToChatBuddyMutex       sync.Mutex
 	userIDToUserMutex            sync.Mutex
-	userIDToRematchGameIDMutex   sync.Mutex
+	userIDToRematchRequestMutex  sync.Mutex
+	positionToGamesCountMutex    sync.Mutex
+	dailyPuzzle                  *dailyPuzzle
+	dailyPuzzleMutex             sync.Mutex
+	retention                    RetentionConfig
 
 	gamesPlayedToday uint64
 	usersJoinedToday uint64
 }
 
-func New(token, mongodbURI string) *Bot {
+func New(token, mongodbURI string, retention RetentionConfig) *Bot {
 	db := database.New(mongodbURI)
+
+	optionsToWaitingPlayer := make(map[othellogame.Options]chan *tgbotapi.User)
+	starts := [...]othellogame.Options{{}, {RandomOpening: true}, {Holes: holes.Random}}
+	for _, size := range othellogame.BoardSizes {
+		for _, v := range variant.Variants {
+			for _, options := range starts {
+				options.BoardSize = size
+				options.Variant = v
+				optionsToWaitingPlayer[options] = make(chan *tgbotapi.User, 1)
+			}
+		}
+	}
+
 	return &Bot{
 		token:                   token,
 		db:                      db,
 		scoreboard:              util.NewScoreboard(db.GetAllPlayers()),
-		waitingPlayer:           make(chan *tgbotapi.User, 1),
+		optionsToWaitingPlayer:  optionsToWaitingPlayer,
 		inlineMessageIDToUser:   make(map[string]*tgbotapi.User),
 		gameIDToGameData:        make(map[string]gameData),
 		gameIDToInlineMessageID: make(map[string]string),
 		userIDToCurrentGame:     make(map[int64]*othellogame.Game),
 		userIDToLastTimeActive:  make(map[int64]time.Time),
-		userIDToMessageID:       make(map[int64]int),
+		userIDToGameMessage:     make(map[int64]gameMessage),
 		userIDToChatBuddy:       make(map[int64]*tgbotapi.User),
 		userIDToUser:            make(map[int64]*tgbotapi.User),
-		userIDToRematchGameID:   make(map[int64]string),
+		userIDToRematchRequest:  make(map[int64]rematchRequest),
+		positionToGamesCount:    make(map[uint64]int),
+		retention:               retention,
 	}
 }
 
@@ -95,7 +142,15 @@ func (bot *Bot) Run() {
 		atomic.SwapUint64(&bot.gamesPlayedToday, 0)
 		atomic.SwapUint64(&bot.usersJoinedToday, 0)
 	})
+	c.AddFunc("@daily", func() {
+		bot.updateDailyPuzzle(loc)
+	})
+	_, err = c.AddFunc(bot.retention.SweepSpec, bot.sweep)
+	if err != nil {
+		log.Panicln(err)
+	}
 	c.Start()
+	go bot.updateDailyPuzzle(loc)
 
 	updateConfig := tgbotapi.NewUpdate(0)
 	updateConfig.Timeout = 60
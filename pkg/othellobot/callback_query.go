@@ -1,9 +1,9 @@
 package othellobot
 
 import (
+	"bytes"
 	"fmt"
 	"log"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -11,8 +11,12 @@ import (
 	"time"
 
 	"github.com/ArminGh02/othello-bot/pkg/consts"
+	"github.com/ArminGh02/othello-bot/pkg/engine"
 	"github.com/ArminGh02/othello-bot/pkg/gifmaker"
 	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
 	"github.com/ArminGh02/othello-bot/pkg/util"
 	"github.com/ArminGh02/othello-bot/pkg/util/coord"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -20,14 +24,12 @@ import (
 
 func (bot *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 	switch query.Data {
-	case "join":
-		bot.startGameOfFriends(query)
-	case "playWithRandomOpponent":
-		bot.playWithRandomOpponent(query)
-	case "cancel":
-		bot.handleCanceledGame(query)
 	case "toggleShowingLegalMoves":
 		bot.toggleShowingLegalMoves(query)
+	case "togglePreviewingFlips":
+		bot.togglePreviewingFlips(query)
+	case "hint":
+		bot.showHint(query)
 	case "surrender":
 		bot.handleSurrender(query)
 	case "end":
@@ -35,16 +37,28 @@ func (bot *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 	case "chat":
 		bot.startChatBetweenOpponents(query)
 	case "gameOver":
-		bot.api.Request(tgbotapi.NewCallback(query.ID, "Game is over!"))
+		bot.api.Request(tgbotapi.NewCallback(query.ID, bot.languageOf(query.From).T("Game is over!")))
+	case "puzzleAnswered":
+		bot.api.Request(tgbotapi.NewCallback(query.ID, bot.languageOf(query.From).T("The next puzzle is at midnight!")))
+	case "puzzleLeaderboard":
+		bot.showPuzzleLeaderboard(query)
 	default:
 		match, _ := regexp.MatchString(`^\d+_\d+$`, query.Data)
 		switch {
 		case match:
 			bot.placeDisk(query)
+		case strings.HasPrefix(query.Data, "join"):
+			creatorColor, opts := parseJoinData(strings.TrimPrefix(query.Data, "join"))
+			bot.startGameOfFriends(query, creatorColor, opts)
+		case strings.HasPrefix(query.Data, "playWithRandomOpponent"):
+			opts := parseGameOptions(strings.TrimPrefix(query.Data, "playWithRandomOpponent"))
+			bot.playWithRandomOpponent(query, opts)
+		case strings.HasPrefix(query.Data, "cancel"):
+			bot.handleCanceledGame(query, parseGameOptions(strings.TrimPrefix(query.Data, "cancel")))
 		case strings.HasPrefix(query.Data, "replay"):
 			text := ""
 			if err := bot.sendGameReplay(query.From, query.Data); err != nil {
-				text = err.Error()
+				text = localizeError(bot.languageOf(query.From), err)
 			}
 			bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
 		case strings.HasPrefix(query.Data, "profile"):
@@ -55,120 +69,258 @@ func (bot *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 			bot.handleAcceptedRematch(query)
 		case strings.HasPrefix(query.Data, "reject"):
 			bot.handleRejectedRematch(query)
+		case strings.HasPrefix(query.Data, "settings:"):
+			bot.changeSetting(query)
+		case strings.HasPrefix(query.Data, "puzzle:"):
+			bot.answerPuzzle(query)
+		case strings.HasPrefix(query.Data, "tutorial:"):
+			bot.handleTutorial(query)
 		}
 	}
 }
 
 func (bot *Bot) sendGameReplay(user *tgbotapi.User, data string) error {
-	gameID := strings.TrimPrefix(data, "replay")
+	gameID, options, _ := strings.Cut(strings.TrimPrefix(data, "replay"), ":")
+	speed, format, _ := strings.Cut(options, ":")
+	opts := gifmaker.DefaultOptions()
+	opts.Speed = replaySpeedOf(bot.db.Find(user.ID))
+	// the options are typed by hand in the links of the replays too
+	if speed != "" {
+		var ok bool
+		if opts.Speed, ok = gifmaker.ParseSpeed(speed); !ok {
+			return errReplayFailed
+		}
+	}
+	if format != "" {
+		var ok bool
+		if opts.Format, ok = gifmaker.ParseFormat(format); !ok {
+			return errReplayFailed
+		}
+	}
+	if !opts.Format.Available() {
+		return errReplayFailed
+	}
 
-	bot.gameIDToMovesSequenceMutex.Lock()
-	gameData, ok := bot.gameIDToGameData[gameID]
-	bot.gameIDToMovesSequenceMutex.Unlock()
+	gameData, ok := bot.findGameData(gameID)
 	if !ok {
 		return errTooOldGame
 	}
+	opts.BoardSize = gameData.boardSize
+	opts.Start = gameData.start
+	opts.Theme = bot.themeOf(user.ID)
 
-	gifFilename := gameID + ".gif"
-	gifmaker.Make(gifFilename, gameData.moveSequence, gameData.whiteStarts)
-
-	gameGIF := tgbotapi.NewAnimation(user.ID, tgbotapi.FilePath(gifFilename))
-	gameGIF.Caption = fmt.Sprintf(
+	lang := bot.languageOf(user)
+	caption := lang.T(
 		"%s White: %s | Score: %d\n%s Black: %s | Score: %d",
-		consts.WhiteDiskEmoji,
+		opts.Theme.Emoji(cell.White),
 		gameData.whitePlayerName,
 		gameData.whiteScore,
-		consts.BlackDiskEmoji,
+		opts.Theme.Emoji(cell.Black),
 		gameData.blackPlayerName,
 		gameData.blackScore,
 	)
-	bot.api.Send(gameGIF)
+	if gameData.opening != "" {
+		caption += "\n" + lang.T("📖 Opening: %s", gameData.opening)
+	}
+	replyMarkup := buildReplayKeyboard(gameID, opts, lang)
+
+	if opts.Format == gifmaker.FormatText {
+		var buf strings.Builder
+		err := gifmaker.Make(&buf, gameData.moveSequence, gameData.whiteStarts, opts)
+		if err != nil {
+			log.Println("Error making replay:", err)
+			return errReplayFailed
+		}
+		msg := tgbotapi.NewMessage(user.ID, caption+"\n\n"+buf.String())
+		msg.ReplyMarkup = replyMarkup
+		if _, err := bot.api.Send(msg); err != nil {
+			log.Println("Error sending replay:", err)
+			return errReplayFailed
+		}
+		return nil
+	}
+
+	key := replayKey(opts)
+	var replay tgbotapi.RequestFileData
+	fileID, cached := gameData.replayFileIDs[key]
+	if cached {
+		replay = tgbotapi.FileID(fileID)
+	} else {
+		var buf bytes.Buffer
+		err := gifmaker.Make(&buf, gameData.moveSequence, gameData.whiteStarts, opts)
+		if err != nil {
+			log.Println("Error making replay:", err)
+			return errReplayFailed
+		}
+		replay = tgbotapi.FileBytes{
+			Name:  gameID + "." + opts.Format.Extension(),
+			Bytes: buf.Bytes(),
+		}
+	}
 
-	err := os.Remove(gifFilename)
+	var replayMsg tgbotapi.Chattable
+	switch opts.Format {
+	case gifmaker.FormatGIF, gifmaker.FormatMP4:
+		animation := tgbotapi.NewAnimation(user.ID, replay)
+		animation.Caption = caption
+		animation.ReplyMarkup = replyMarkup
+		replayMsg = animation
+	default:
+		// Telegram would convert other animated images to still ones,
+		// so they are sent as files.
+		document := tgbotapi.NewDocument(user.ID, replay)
+		document.Caption = caption
+		document.ReplyMarkup = replyMarkup
+		replayMsg = document
+	}
+	msg, err := bot.api.Send(replayMsg)
 	if err != nil {
-		log.Panicln(err)
+		log.Println("Error sending replay:", err)
+		return errReplayFailed
 	}
 
+	if !cached {
+		switch {
+		case msg.Animation != nil:
+			bot.cacheReplayFileID(gameID, key, msg.Animation.FileID)
+		case msg.Document != nil:
+			bot.cacheReplayFileID(gameID, key, msg.Document.FileID)
+		}
+	}
 	return nil
 }
 
+// replayKey is the key of the file ID of a replay sent with opts. Classic
+// GIF replays are keyed by speed alone, as they were before other formats
+// and themes.
+func replayKey(opts gifmaker.Options) string {
+	key := string(opts.Speed)
+	if opts.Format != gifmaker.FormatGIF {
+		key += "_" + string(opts.Format)
+	}
+	if opts.Theme != theme.Classic {
+		key += "_" + string(opts.Theme)
+	}
+	return key
+}
+
 func (bot *Bot) placeDisk(query *tgbotapi.CallbackQuery) {
 	user := query.From
 
 	bot.userIDToCurrentGameMutex.Lock()
 	defer bot.userIDToCurrentGameMutex.Unlock()
 
+	lang := bot.languageOf(user)
 	game, ok := bot.userIDToCurrentGame[user.ID]
 	if !ok {
-		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, errTooOldGame.Error()))
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, errTooOldGame)))
+		return
+	}
+	if !bot.isCurrentBoard(game, user, query) {
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, errTooOldGame)))
 		return
 	}
 
 	var where coord.Coord
 	fmt.Sscanf(query.Data, "%d_%d", &where.X, &where.Y)
 
-	err := game.PlaceDisk(where, user)
+	// moves are placed by a second tap on the selected cell for players who
+	// confirm moves or preview flips, and tapping other cells selects them
+	selected, ok := game.Selected()
+	player := bot.db.Find(user.ID)
+	if game.IsTurnOf(user) && game.IsLegalMove(where) && (!ok || selected != where) &&
+		(player.ConfirmsMoves || player.FlipsArePreviewed) {
+		game.Select(where, player.FlipsArePreviewed)
+		render := bot.runningGameRender(game, query.InlineMessageID != "")
+		bot.sendEditMessageTextForGame(game, render, query.InlineMessageID)
+		bot.api.Request(tgbotapi.NewCallback(query.ID, lang.T("Tap again to place the disk.")))
+		return
+	}
+
+	passed, err := game.PlaceDisk(where, user)
 	if err != nil {
-		bot.api.Request(tgbotapi.NewCallback(query.ID, err.Error()))
+		bot.api.Request(tgbotapi.NewCallback(query.ID, localizeError(lang, err)))
 	} else if game.IsEnded() {
 		bot.handleGameEnd(game, query)
 	} else {
 		bot.userIDToLastTimeActiveMutex.Lock()
-		bot.userIDToLastTimeActive[game.OpponentOf(user).ID] = time.Now()
+		// the player moves again when the opponent passes
+		bot.userIDToLastTimeActive[game.ActiveUser().ID] = time.Now()
 		bot.userIDToLastTimeActiveMutex.Unlock()
 
-		msg, replyMarkup := getRunningGameMsgAndReplyMarkup(
-			game,
-			bot.db.LegalMovesAreShown(game.ActiveUser().ID),
-			query.InlineMessageID != "",
-		)
 		bot.sendEditMessageTextForGame(
-			msg,
-			replyMarkup,
-			game.WhiteUser(),
-			game.BlackUser(),
+			game,
+			bot.runningGameRender(game, query.InlineMessageID != ""),
 			query.InlineMessageID,
 		)
-		bot.api.Request(tgbotapi.NewCallback(query.ID, "Disk placed!"))
+		answer := lang.T("Disk placed!")
+		if passed {
+			answer += " " + passText(lang, bot.themeOf(user.ID), game)
+		}
+		bot.api.Request(tgbotapi.NewCallback(query.ID, answer))
 	}
 }
 
-func (bot *Bot) handleGameEnd(game *othellogame.Game, query *tgbotapi.CallbackQuery) {
-	winner, loser := game.Winner(), game.Loser()
-	if winner == nil {
-		bot.db.IncrementDraws(game.WhiteUser().ID)
-		bot.db.IncrementDraws(game.BlackUser().ID)
-	} else {
-		bot.db.IncrementWins(winner.ID)
-		bot.db.IncrementLosses(loser.ID)
-		bot.scoreboard.UpdateRankOf(winner.ID, 1, 0)
-		bot.scoreboard.UpdateRankOf(loser.ID, 0, 1)
+// isCurrentBoard reports whether the query is from the message showing the
+// board of game now, rather than from an old board or another game's.
+func (bot *Bot) isCurrentBoard(game *othellogame.Game, user *tgbotapi.User, query *tgbotapi.CallbackQuery) bool {
+	if query.InlineMessageID != "" {
+		bot.gameIDToInlineMessageIDMutex.Lock()
+		defer bot.gameIDToInlineMessageIDMutex.Unlock()
+		return bot.gameIDToInlineMessageID[game.ID()] == query.InlineMessageID
 	}
 
-	bot.gameIDToMovesSequenceMutex.Lock()
-	bot.gameIDToGameData[game.ID()] = newGameData(game)
-	bot.gameIDToMovesSequenceMutex.Unlock()
+	bot.userIDToGameMessageMutex.Lock()
+	defer bot.userIDToGameMessageMutex.Unlock()
+	message, ok := bot.userIDToGameMessage[user.ID]
+	return ok && query.Message != nil && query.Message.MessageID == message.id
+}
+
+func (bot *Bot) handleGameEnd(game *othellogame.Game, query *tgbotapi.CallbackQuery) {
+	bot.recordResult(game, game.Winner(), game.Loser())
+	bot.storeGameData(game)
 
-	msg, replyMarkup := getGameOverMsgAndReplyMarkup(
-		game,
-		bot.api.Self.UserName,
-		query.InlineMessageID != "",
-	)
 	bot.sendEditMessageTextForGame(
-		msg,
-		replyMarkup,
-		game.WhiteUser(),
-		game.BlackUser(),
+		game,
+		gameOverRender(game, bot.api.Self.UserName, query.InlineMessageID != ""),
 		query.InlineMessageID,
 	)
 
-	bot.api.Request(tgbotapi.NewCallback(query.ID, "Game is over!"))
+	bot.api.Request(tgbotapi.NewCallback(query.ID, bot.languageOf(query.From).T("Game is over!")))
 
 	bot.cleanUp(game, query)
 	log.Println(game, "is over.")
 	atomic.AddUint64(&bot.gamesPlayedToday, 1)
 }
 
+// recordResult updates the stats of the players of game, where a nil winner
+// means a draw. Anti-Othello games are tracked apart from the classic ones,
+// which alone count on the scoreboard.
+func (bot *Bot) recordResult(game *othellogame.Game, winner, loser *tgbotapi.User) {
+	bot.recordColorResults(game, winner)
+
+	if game.Variant() == variant.Anti {
+		if winner == nil {
+			bot.db.IncrementAntiDraws(game.WhiteUser().ID)
+			bot.db.IncrementAntiDraws(game.BlackUser().ID)
+		} else {
+			bot.db.IncrementAntiWins(winner.ID)
+			bot.db.IncrementAntiLosses(loser.ID)
+		}
+		return
+	}
+
+	if winner == nil {
+		bot.db.IncrementDraws(game.WhiteUser().ID)
+		bot.db.IncrementDraws(game.BlackUser().ID)
+	} else {
+		bot.db.IncrementWins(winner.ID)
+		bot.db.IncrementLosses(loser.ID)
+		bot.scoreboard.UpdateRankOf(winner.ID, 1, 0)
+		bot.scoreboard.UpdateRankOf(loser.ID, 0, 1)
+	}
+}
+
 func (bot *Bot) cleanUp(game *othellogame.Game, query *tgbotapi.CallbackQuery) {
 	user1 := game.WhiteUser()
 	user2 := game.BlackUser()
@@ -185,48 +337,58 @@ func (bot *Bot) cleanUp(game *othellogame.Game, query *tgbotapi.CallbackQuery) {
 		delete(bot.gameIDToInlineMessageID, game.ID())
 		bot.gameIDToInlineMessageIDMutex.Unlock()
 	} else {
-		bot.userIDToMessageIDMutex.Lock()
-		delete(bot.userIDToMessageID, user1.ID)
-		delete(bot.userIDToMessageID, user2.ID)
-		bot.userIDToMessageIDMutex.Unlock()
+		bot.userIDToGameMessageMutex.Lock()
+		delete(bot.userIDToGameMessage, user1.ID)
+		delete(bot.userIDToGameMessage, user2.ID)
+		bot.userIDToGameMessageMutex.Unlock()
 	}
 }
 
-func (bot *Bot) startGameOfFriends(query *tgbotapi.CallbackQuery) {
+// startGameOfFriends starts the game of the inline message the query is
+// from, in which its creator plays creatorColor.
+func (bot *Bot) startGameOfFriends(
+	query *tgbotapi.CallbackQuery,
+	creatorColor cell.Cell,
+	opts othellogame.Options,
+) {
 	bot.inlineMessageIDToUserMutex.Lock()
 	user1, ok := bot.inlineMessageIDToUser[query.InlineMessageID]
 	bot.inlineMessageIDToUserMutex.Unlock()
 
+	lang := bot.languageOf(query.From)
 	if !ok {
-		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, "Invitation is too old!"))
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, lang.T("Invitation is too old!")))
 		return
 	}
 
 	user2 := query.From
 
 	if *user1 == *user2 {
-		text := "You can't play with yourself!"
+		text := lang.T("You can't play with yourself!")
 		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
 		return
 	}
 
 	if _, ok := bot.userIDToCurrentGame[user1.ID]; ok {
-		text := util.FirstNameElseLastName(user1) + " is playing another game"
+		text := localizeError(lang, errPlayingAnotherGame{user1})
 		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
 		return
 	}
 	if _, ok := bot.userIDToCurrentGame[user2.ID]; ok {
-		text := util.FirstNameElseLastName(user2) + " is playing another game"
+		text := localizeError(lang, errPlayingAnotherGame{user2})
 		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
 		return
 	}
 
-	if bot.db.AddPlayer(user2.ID, util.FullNameOf(user2)) {
-		bot.scoreboard.Insert(bot.db.Find(user2.ID))
-		atomic.AddUint64(&bot.usersJoinedToday, 1)
-	}
+	bot.ensurePlayer(user2)
 
-	game := othellogame.New(user1, user2)
+	var white, black *tgbotapi.User
+	if opts.Handicap > 0 && creatorColor == cell.Empty {
+		white, black = bot.handicapColors(user1, user2)
+	} else {
+		white, black = chosenColors(user1, user2, creatorColor)
+	}
+	game := othellogame.NewWithOptions(white, black, opts)
 
 	log.Printf("Started %v.\n", game)
 
@@ -251,28 +413,35 @@ func (bot *Bot) startGameOfFriends(query *tgbotapi.CallbackQuery) {
 	bot.userIDToCurrentGame[user1.ID] = game
 	bot.userIDToCurrentGame[user2.ID] = game
 
-	msg, replyMarkup := getRunningGameMsgAndReplyMarkup(
-		game,
-		bot.db.LegalMovesAreShown(game.ActiveUser().ID),
-		query.InlineMessageID != "",
-	)
-	bot.sendEditMessageTextForGame(msg, replyMarkup, user1, user2, query.InlineMessageID)
+	render := bot.runningGameRender(game, query.InlineMessageID != "")
+	bot.sendEditMessageTextForGame(game, render, query.InlineMessageID)
 
 	bot.api.Request(tgbotapi.CallbackConfig{
 		CallbackQueryID: query.ID,
 	})
 }
 
-func (bot *Bot) playWithRandomOpponent(query *tgbotapi.CallbackQuery) {
+func (bot *Bot) playWithRandomOpponent(query *tgbotapi.CallbackQuery, opts othellogame.Options) {
 	user1 := query.From
+	lang := bot.languageOf(user1)
+	waitingPlayer, ok := bot.optionsToWaitingPlayer[opts]
+	if !ok {
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, errInvalidGameOptions)))
+		return
+	}
 
-	if len(bot.waitingPlayer) == 0 {
-		bot.waitingPlayer <- user1
+	if len(waitingPlayer) == 0 {
+		waitingPlayer <- user1
 
-		msg := tgbotapi.NewMessage(user1.ID, "Wait until another player joins the game.")
+		msgText := lang.T("Wait until another player joins the game.")
+		if opts != othellogame.DefaultOptions() {
+			msgText = lang.T(
+				"Wait until another player joins the %s game.", gameOptionsLabel(opts, lang))
+		}
+		msg := tgbotapi.NewMessage(user1.ID, msgText)
 		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Cancel", "cancel"),
+				tgbotapi.NewInlineKeyboardButtonData(lang.T("Cancel"), "cancel"+formatGameOptions(opts)),
 			),
 		)
 		bot.api.Send(msg)
@@ -283,31 +452,38 @@ func (bot *Bot) playWithRandomOpponent(query *tgbotapi.CallbackQuery) {
 		return
 	}
 
-	user2 := <-bot.waitingPlayer
+	user2 := <-waitingPlayer
 
 	if *user1 == *user2 {
-		text := "You can't play with yourself!"
+		text := lang.T("You can't play with yourself!")
 		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
-		bot.waitingPlayer <- user2
+		waitingPlayer <- user2
 		return
 	}
 
 	text := ""
-	if err := bot.startGameOfRandomOpponents(user1, user2); err != nil {
-		text = err.Error()
+	white, black := bot.balancedColors(user1, user2)
+	if err := bot.startGameOfRandomOpponents(white, black, opts); err != nil {
+		text = localizeError(lang, err)
 	}
 	bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
 }
 
-func (bot *Bot) startGameOfRandomOpponents(user1, user2 *tgbotapi.User) error {
+// startGameOfRandomOpponents starts a game in private chats,
+// in which user1 plays white and user2 plays black.
+func (bot *Bot) startGameOfRandomOpponents(
+	user1, user2 *tgbotapi.User,
+	opts othellogame.Options,
+) error {
 	if _, ok := bot.userIDToCurrentGame[user1.ID]; ok {
-		return fmt.Errorf("%s is playing another game", util.FirstNameElseLastName(user1))
+		return errPlayingAnotherGame{user1}
 	}
 	if _, ok := bot.userIDToCurrentGame[user2.ID]; ok {
-		return fmt.Errorf("%s is playing another game", util.FirstNameElseLastName(user2))
+		return errPlayingAnotherGame{user2}
 	}
 
-	game := othellogame.New(user1, user2)
+	opts.Rated = true
+	game := othellogame.NewWithOptions(user1, user2, opts)
 
 	log.Printf("Started %s.\n", game)
 
@@ -328,123 +504,218 @@ func (bot *Bot) startGameOfRandomOpponents(user1, user2 *tgbotapi.User) error {
 	bot.userIDToCurrentGame[user1.ID] = game
 	bot.userIDToCurrentGame[user2.ID] = game
 
-	msgText, replyMarkup := getRunningGameMsgAndReplyMarkup(
-		game, bot.db.LegalMovesAreShown(game.ActiveUser().ID), false)
-	msg1 := tgbotapi.NewMessage(user1.ID, msgText)
-	msg2 := tgbotapi.NewMessage(user2.ID, msgText)
-	msg1.ReplyMarkup = replyMarkup
-	msg2.ReplyMarkup = replyMarkup
+	render := bot.runningGameRender(game, false)
+	bot.sendGameMessage(game, user1, render)
+	bot.sendGameMessage(game, user2, render)
 
-	msg, _ := bot.api.Send(msg1)
-	bot.userIDToMessageIDMutex.Lock()
-	bot.userIDToMessageID[user1.ID] = msg.MessageID
-	bot.userIDToMessageIDMutex.Unlock()
+	return nil
+}
 
-	msg, _ = bot.api.Send(msg2)
-	bot.userIDToMessageIDMutex.Lock()
-	bot.userIDToMessageID[user2.ID] = msg.MessageID
-	bot.userIDToMessageIDMutex.Unlock()
+func (bot *Bot) sendGameMessage(
+	game *othellogame.Game,
+	user *tgbotapi.User,
+	render gameRender,
+) {
+	v := bot.viewerOf(user)
+	msgText, replyMarkup := render(v)
+	var msg tgbotapi.Chattable
+	isPhoto := bot.db.BoardIsImage(user.ID) || !fitsKeyboard(game)
+	if isPhoto {
+		photo, err := boardPhoto(game, v)
+		if err != nil {
+			log.Println("Error rendering board:", err)
+			isPhoto = false
+		} else {
+			photoMsg := tgbotapi.NewPhoto(user.ID, photo)
+			photoMsg.Caption = msgText
+			photoMsg.ReplyMarkup = replyMarkup(true)
+			msg = photoMsg
+		}
+	}
+	if !isPhoto {
+		text, markup := textGameMessage(game, msgText, replyMarkup, v.theme)
+		textMsg := tgbotapi.NewMessage(user.ID, text)
+		textMsg.ReplyMarkup = markup
+		msg = textMsg
+	}
+	if bot.db.Find(user.ID).SilentGameMessages {
+		switch m := msg.(type) {
+		case tgbotapi.PhotoConfig:
+			m.DisableNotification = true
+			msg = m
+		case tgbotapi.MessageConfig:
+			m.DisableNotification = true
+			msg = m
+		}
+	}
 
-	return nil
+	sent, _ := bot.api.Send(msg)
+	bot.userIDToGameMessageMutex.Lock()
+	bot.userIDToGameMessage[user.ID] = gameMessage{
+		id:      sent.MessageID,
+		isPhoto: isPhoto,
+	}
+	bot.userIDToGameMessageMutex.Unlock()
 }
 
-func (bot *Bot) handleCanceledGame(query *tgbotapi.CallbackQuery) {
+func (bot *Bot) handleCanceledGame(query *tgbotapi.CallbackQuery, opts othellogame.Options) {
 	defer bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
 
-	if len(bot.waitingPlayer) == 0 {
+	waitingPlayers, ok := bot.optionsToWaitingPlayer[opts]
+	if !ok || len(waitingPlayers) == 0 {
 		return
 	}
 
-	waitingPlayer := <-bot.waitingPlayer
+	waitingPlayer := <-waitingPlayers
 	if *waitingPlayer == *query.From {
 		bot.api.Send(
 			tgbotapi.NewEditMessageTextAndMarkup(
 				query.From.ID,
 				query.Message.MessageID,
-				"Request was canceled.",
+				bot.languageOf(query.From).T("Request was canceled."),
 				util.RemoveInlineKeyboardMarkup(),
 			),
 		)
 	} else {
-		bot.waitingPlayer <- waitingPlayer
+		waitingPlayers <- waitingPlayer
 	}
 }
 
 func (bot *Bot) toggleShowingLegalMoves(query *tgbotapi.CallbackQuery) {
 	user := query.From
+	lang := bot.languageOf(user)
 
 	bot.userIDToCurrentGameMutex.Lock()
 	defer bot.userIDToCurrentGameMutex.Unlock()
 
 	game, ok := bot.userIDToCurrentGame[user.ID]
 	if !ok {
-		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, errTooOldGame.Error()))
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, errTooOldGame)))
 		return
 	}
 
 	bot.db.ToggleLegalMovesAreShown(user.ID)
 
 	if game.IsTurnOf(user) {
-		msg, replyMarkup := getRunningGameMsgAndReplyMarkup(
-			game,
-			bot.db.LegalMovesAreShown(game.ActiveUser().ID),
-			query.InlineMessageID != "",
-		)
 		bot.sendEditMessageTextForGame(
-			msg,
-			replyMarkup,
-			game.WhiteUser(),
-			game.BlackUser(),
+			game,
+			bot.runningGameRender(game, query.InlineMessageID != ""),
 			query.InlineMessageID,
 		)
 	}
 
-	bot.api.Request(tgbotapi.NewCallback(query.ID, "Toggled for you!"))
+	bot.api.Request(tgbotapi.NewCallback(query.ID, lang.T("Toggled for you!")))
+}
+
+func (bot *Bot) togglePreviewingFlips(query *tgbotapi.CallbackQuery) {
+	user := query.From
+	lang := bot.languageOf(user)
+
+	bot.userIDToCurrentGameMutex.Lock()
+	defer bot.userIDToCurrentGameMutex.Unlock()
+
+	game, ok := bot.userIDToCurrentGame[user.ID]
+	if !ok {
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, errTooOldGame)))
+		return
+	}
+
+	bot.db.ToggleFlipsArePreviewed(user.ID)
+
+	if game.IsTurnOf(user) {
+		render := bot.runningGameRender(game, query.InlineMessageID != "")
+		bot.sendEditMessageTextForGame(game, render, query.InlineMessageID)
+	}
+
+	bot.api.Request(tgbotapi.NewCallback(query.ID, lang.T("Toggled for you!")))
+}
+
+// showHint marks the move the engine suggests to the active player of
+// an unrated game, who can use a few hints per game.
+func (bot *Bot) showHint(query *tgbotapi.CallbackQuery) {
+	user := query.From
+	lang := bot.languageOf(user)
+
+	bot.userIDToCurrentGameMutex.Lock()
+	defer bot.userIDToCurrentGameMutex.Unlock()
+
+	game, ok := bot.userIDToCurrentGame[user.ID]
+	if !ok {
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, errTooOldGame)))
+		return
+	}
+
+	switch {
+	case game.IsRated():
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, lang.T("Hints are disabled in rated games.")))
+		return
+	case !game.IsTurnOf(user):
+		bot.api.Request(tgbotapi.NewCallback(query.ID, localizeError(lang, othellogame.ErrNotYourTurn)))
+		return
+	case game.HintsUsed(user) >= maxHintsPerGame:
+		text := lang.T("You have used all your %d hints in this game.", maxHintsPerGame)
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
+		return
+	}
+
+	if _, ok := game.Hint(); !ok {
+		move, _ := engine.BestMove(game, hintDepth)
+		game.MarkHint(move)
+
+		render := bot.runningGameRender(game, query.InlineMessageID != "")
+		bot.sendEditMessageTextForGame(game, render, query.InlineMessageID)
+	}
+
+	hint, _ := game.Hint()
+	text := lang.T(
+		"%s Try %s! Hints left: %d",
+		consts.HintEmoji,
+		hint,
+		maxHintsPerGame-game.HintsUsed(user),
+	)
+	bot.api.Request(tgbotapi.NewCallback(query.ID, text))
 }
 
 func (bot *Bot) alertProfile(query *tgbotapi.CallbackQuery) {
 	userID, _ := strconv.ParseInt(strings.TrimPrefix(query.Data, "profile"), 10, 64)
 	rank := bot.scoreboard.RankOf(userID)
-	bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, bot.db.Find(userID).String(rank)))
+	profile := profileText(bot.db.Find(userID), rank, bot.languageOf(query.From))
+	bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, profile))
 }
 
 func (bot *Bot) handleSurrender(query *tgbotapi.CallbackQuery) {
 	loser := query.From
+	lang := bot.languageOf(loser)
 
 	bot.userIDToCurrentGameMutex.Lock()
 
 	game, ok := bot.userIDToCurrentGame[loser.ID]
 	if !ok {
-		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, errTooOldGame.Error()))
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, errTooOldGame)))
 		bot.userIDToCurrentGameMutex.Unlock()
 		return
 	}
 
-	bot.gameIDToMovesSequenceMutex.Lock()
-	bot.gameIDToGameData[game.ID()] = newGameData(game)
-	bot.gameIDToMovesSequenceMutex.Unlock()
+	bot.storeGameData(game)
 
 	winner := game.OpponentOf(loser)
 
-	msg, replyMarkup := getSurrenderMsgAndReplyMarkup(
+	render := surrenderRender(
 		game,
 		winner,
 		loser,
 		bot.api.Self.UserName,
 		query.InlineMessageID != "",
 	)
-	bot.sendEditMessageTextForGame(msg, replyMarkup, winner, loser, query.InlineMessageID)
+	bot.sendEditMessageTextForGame(game, render, query.InlineMessageID)
 
-	bot.api.Request(tgbotapi.NewCallback(query.ID, "You surrendered!"))
+	bot.api.Request(tgbotapi.NewCallback(query.ID, lang.T("You surrendered!")))
 
 	bot.cleanUp(game, query)
 
 	bot.userIDToCurrentGameMutex.Unlock()
 
-	bot.db.IncrementWins(winner.ID)
-	bot.db.IncrementLosses(loser.ID)
-	bot.scoreboard.UpdateRankOf(winner.ID, 1, 0)
-	bot.scoreboard.UpdateRankOf(loser.ID, 0, 1)
+	bot.recordResult(game, winner, loser)
 
 	log.Printf("%s surrendered in %v.\n", loser, game)
 	atomic.AddUint64(&bot.gamesPlayedToday, 1)
@@ -455,16 +726,17 @@ func (bot *Bot) handleEndEarly(query *tgbotapi.CallbackQuery) {
 	defer bot.userIDToCurrentGameMutex.Unlock()
 
 	user1 := query.From
+	lang := bot.languageOf(user1)
 
 	game, ok := bot.userIDToCurrentGame[user1.ID]
 	if !ok {
-		bot.api.Request(tgbotapi.NewCallback(query.ID, errTooOldGame.Error()))
+		bot.api.Request(tgbotapi.NewCallback(query.ID, localizeError(lang, errTooOldGame)))
 		return
 	}
 
 	if game.IsTurnOf(user1) {
 		bot.api.Request(
-			tgbotapi.NewCallback(query.ID, "You can't end the game in your turn."),
+			tgbotapi.NewCallback(query.ID, lang.T("You can't end the game in your turn.")),
 		)
 		return
 	}
@@ -475,40 +747,28 @@ func (bot *Bot) handleEndEarly(query *tgbotapi.CallbackQuery) {
 	lastActiveTime := bot.userIDToLastTimeActive[user2.ID]
 	bot.userIDToLastTimeActiveMutex.Unlock()
 
+	limit := bot.moveTimeLimitOfGame(game)
 	secondsSinceLastActive := time.Since(lastActiveTime).Seconds()
-	if secondsSinceLastActive > 90 {
-		bot.gameIDToMovesSequenceMutex.Lock()
-		bot.gameIDToGameData[game.ID()] = newGameData(game)
-		bot.gameIDToMovesSequenceMutex.Unlock()
+	if secondsSinceLastActive > float64(limit) {
+		bot.storeGameData(game)
 
-		msg, replyMarkup := getEarlyEndMsgAndReplyMarkup(
-			game,
-			user2,
-			bot.api.Self.UserName,
-			query.InlineMessageID != "",
-		)
 		bot.sendEditMessageTextForGame(
-			msg,
-			replyMarkup,
-			user1,
-			user2,
+			game,
+			earlyEndRender(game, user2, bot.api.Self.UserName, query.InlineMessageID != ""),
 			query.InlineMessageID,
 		)
 
 		bot.cleanUp(game, query)
 
-		bot.db.IncrementWins(user1.ID)
-		bot.db.IncrementLosses(user2.ID)
-		bot.scoreboard.UpdateRankOf(user1.ID, 1, 0)
-		bot.scoreboard.UpdateRankOf(user2.ID, 0, 1)
+		bot.recordResult(game, user1, user2)
 
 		bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
 		atomic.AddUint64(&bot.gamesPlayedToday, 1)
 		log.Printf("%s ended %v.\n", user1, game)
 	} else {
-		msg := fmt.Sprintf("You can end the game if your "+
+		msg := lang.T("You can end the game if your "+
 			"opponent doesn't place a disk for %d seconds.",
-			90-int(secondsSinceLastActive),
+			limit-int(secondsSinceLastActive),
 		)
 		bot.api.Request(tgbotapi.NewCallback(query.ID, msg))
 	}
@@ -516,14 +776,15 @@ func (bot *Bot) handleEndEarly(query *tgbotapi.CallbackQuery) {
 
 func (bot *Bot) startChatBetweenOpponents(query *tgbotapi.CallbackQuery) {
 	user1 := query.From
+	lang := bot.languageOf(user1)
 	user2, err := bot.opponentOf(user1)
 	if err != nil {
-		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, err.Error()))
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, err)))
 		return
 	}
 
-	msg := tgbotapi.NewMessage(user1.ID, "Chat with your opponent:")
-	buttonText := fmt.Sprint("End chat with ", util.FirstNameElseLastName(user2))
+	msg := tgbotapi.NewMessage(user1.ID, lang.T("Chat with your opponent:"))
+	buttonText := lang.T(endChatButtonText) + " " + util.FirstNameElseLastName(user2)
 	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton(buttonText),
@@ -552,43 +813,56 @@ func (bot *Bot) handleRematch(query *tgbotapi.CallbackQuery) {
 	otherUser, ok := bot.userIDToUser[otherUserID]
 	bot.userIDToUserMutex.Unlock()
 
+	lang := bot.languageOf(query.From)
 	if !ok {
-		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, errTooOldGame.Error()))
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, localizeError(lang, errTooOldGame)))
+		return
+	}
+
+	if bot.db.Find(otherUserID).RejectsRematches {
+		text := lang.T("%s doesn't accept rematch requests.", util.FirstNameElseLastName(otherUser))
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
 		return
 	}
 
-	bot.userIDToRematchGameIDMutex.Lock()
-	otherUserGameID := bot.userIDToRematchGameID[otherUserID]
-	bot.userIDToRematchGameIDMutex.Unlock()
-	if otherUserGameID == gameID { // other user has also requested rematch; start the game
-		bot.userIDToRematchGameIDMutex.Lock()
-		delete(bot.userIDToRematchGameID, user1ID)
-		delete(bot.userIDToRematchGameID, user2ID)
-		bot.userIDToRematchGameIDMutex.Unlock()
+	bot.userIDToRematchRequestMutex.Lock()
+	otherUserRequest := bot.userIDToRematchRequest[otherUserID]
+	bot.userIDToRematchRequestMutex.Unlock()
+	if otherUserRequest.gameID == gameID { // other user has also requested rematch; start the game
+		bot.userIDToRematchRequestMutex.Lock()
+		delete(bot.userIDToRematchRequest, user1ID)
+		delete(bot.userIDToRematchRequest, user2ID)
+		bot.userIDToRematchRequestMutex.Unlock()
 
 		text := ""
-		if err := bot.startGameOfRandomOpponents(query.From, otherUser); err != nil {
-			text = err.Error()
+		white, black := bot.rematchPlayers(gameID, query.From, otherUser)
+		err := bot.startGameOfRandomOpponents(white, black, bot.gameOptionsOf(gameID))
+		if err != nil {
+			text = localizeError(lang, err)
 		}
 		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
 	} else {
-		bot.userIDToRematchGameIDMutex.Lock()
-		bot.userIDToRematchGameID[query.From.ID] = gameID
-		bot.userIDToRematchGameIDMutex.Unlock()
+		bot.userIDToRematchRequestMutex.Lock()
+		bot.userIDToRematchRequest[query.From.ID] = rematchRequest{
+			gameID:      gameID,
+			requestedAt: time.Now(),
+		}
+		bot.userIDToRematchRequestMutex.Unlock()
 
-		msgText := fmt.Sprintf(
+		otherLang := bot.languageOf(otherUser)
+		msgText := otherLang.T(
 			"%s wants to rematch", util.FirstNameElseLastName(query.From))
 		msg := tgbotapi.NewMessage(otherUserID, msgText)
 		id := strconv.FormatInt(query.From.ID, 10)
 		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Accept", "accept"+id),
-				tgbotapi.NewInlineKeyboardButtonData("Reject", "reject"+id),
+				tgbotapi.NewInlineKeyboardButtonData(otherLang.T("Accept"), "accept"+id),
+				tgbotapi.NewInlineKeyboardButtonData(otherLang.T("Reject"), "reject"+id),
 			),
 		)
 		bot.api.Send(msg)
 
-		text := "Wait for your opponent's response."
+		text := lang.T("Wait for your opponent's response.")
 		bot.api.Request(tgbotapi.NewCallback(query.ID, text))
 	}
 }
@@ -600,15 +874,18 @@ func (bot *Bot) handleAcceptedRematch(query *tgbotapi.CallbackQuery) {
 	otherUser, ok := bot.userIDToUser[otherUserID]
 	bot.userIDToUserMutex.Unlock()
 	if !ok {
-		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, errTooOldGame.Error()))
+		text := localizeError(bot.languageOf(query.From), errTooOldGame)
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, text))
 		return
 	}
 
-	bot.userIDToRematchGameIDMutex.Lock()
-	delete(bot.userIDToRematchGameID, otherUserID)
-	bot.userIDToRematchGameIDMutex.Unlock()
+	bot.userIDToRematchRequestMutex.Lock()
+	request := bot.userIDToRematchRequest[otherUserID]
+	delete(bot.userIDToRematchRequest, otherUserID)
+	bot.userIDToRematchRequestMutex.Unlock()
 
-	bot.startGameOfRandomOpponents(query.From, otherUser)
+	white, black := bot.rematchPlayers(request.gameID, query.From, otherUser)
+	bot.startGameOfRandomOpponents(white, black, bot.gameOptionsOf(request.gameID))
 
 	bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
 }
@@ -618,13 +895,14 @@ func (bot *Bot) handleRejectedRematch(query *tgbotapi.CallbackQuery) {
 
 	otherUserID, _ := strconv.ParseInt(strings.TrimPrefix(query.Data, "reject"), 10, 64)
 
-	bot.userIDToRematchGameIDMutex.Lock()
-	delete(bot.userIDToRematchGameID, otherUserID)
-	bot.userIDToRematchGameIDMutex.Unlock()
+	bot.userIDToRematchRequestMutex.Lock()
+	delete(bot.userIDToRematchRequest, otherUserID)
+	bot.userIDToRematchRequestMutex.Unlock()
 
-	msg := "Rematch request was rejected."
+	msg := bot.languageOf(query.From).T("Rematch request was rejected.")
 	bot.api.Send(tgbotapi.NewEditMessageText(query.From.ID, query.Message.MessageID, msg))
 
-	msg = util.FirstNameElseLastName(query.From) + " rejected the rematch request."
+	msg = bot.languageOfID(otherUserID).T(
+		"%s rejected the rematch request.", util.FirstNameElseLastName(query.From))
 	bot.api.Send(tgbotapi.NewMessage(otherUserID, msg))
 }
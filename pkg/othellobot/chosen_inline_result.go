@@ -1,7 +1,6 @@
 package othellobot
 
 import (
-	"fmt"
 	"log"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -37,7 +36,7 @@ func (bot *Bot) handleChosenInlineResult(chosenInlineResult *tgbotapi.ChosenInli
 		BaseEdit: tgbotapi.BaseEdit{
 			InlineMessageID: oldID,
 		},
-		Text: fmt.Sprintf("%v has been moved down 🔽", game),
+		Text: bot.languageOf(user).T("%v has been moved down 🔽", game),
 	})
 
 	bot.userIDToCurrentGameMutex.Unlock()
@@ -0,0 +1,100 @@
+package othellobot
+
+import (
+	"github.com/ArminGh02/othello-bot/pkg/database"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/turn"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// chosenColors returns the white and black players of a game in which
+// user1 chose to play color, which is cell.Empty for a random color.
+func chosenColors(user1, user2 *tgbotapi.User, color cell.Cell) (white, black *tgbotapi.User) {
+	switch color {
+	case cell.White:
+		return user1, user2
+	case cell.Black:
+		return user2, user1
+	default:
+		return randomColors(user1, user2)
+	}
+}
+
+func randomColors(user1, user2 *tgbotapi.User) (white, black *tgbotapi.User) {
+	if turn.Random() == turn.White {
+		return user1, user2
+	}
+	return user2, user1
+}
+
+// balancedColors gives white to the player who has played it less often
+// compared to black, so the colors of players even out over their games
+// with random opponents.
+func (bot *Bot) balancedColors(user1, user2 *tgbotapi.User) (white, black *tgbotapi.User) {
+	balance1 := colorBalance(bot.db.Find(user1.ID))
+	balance2 := colorBalance(bot.db.Find(user2.ID))
+	switch {
+	case balance1 < balance2:
+		return user1, user2
+	case balance1 > balance2:
+		return user2, user1
+	default:
+		return randomColors(user1, user2)
+	}
+}
+
+// colorBalance is how many more games the player has played as white than
+// as black.
+func colorBalance(doc *database.PlayerDoc) int {
+	return doc.WhiteGames() - doc.BlackGames()
+}
+
+// rematchColors swaps the colors the players had in the game they rematch.
+func (bot *Bot) rematchColors(gameID string, user1, user2 *tgbotapi.User) (white, black *tgbotapi.User) {
+	data, ok := bot.findGameData(gameID)
+	switch {
+	case !ok || data.whitePlayerID == 0:
+		// games saved before the IDs of players
+		return bot.balancedColors(user1, user2)
+	case data.whitePlayerID == user1.ID:
+		return user2, user1
+	default:
+		return user1, user2
+	}
+}
+
+// rematchPlayers returns the white and black players of the rematch of
+// a game, which is played with the same handicap or alternating colors.
+func (bot *Bot) rematchPlayers(gameID string, user1, user2 *tgbotapi.User) (white, black *tgbotapi.User) {
+	if bot.gameOptionsOf(gameID).Handicap > 0 {
+		return bot.handicapColors(user1, user2)
+	}
+	return bot.rematchColors(gameID, user1, user2)
+}
+
+// handicapColors gives black, which the handicap disks are, to the weaker
+// player.
+func (bot *Bot) handicapColors(user1, user2 *tgbotapi.User) (white, black *tgbotapi.User) {
+	if bot.db.Find(user2.ID).Score() > bot.db.Find(user1.ID).Score() {
+		return user2, user1
+	}
+	return user1, user2
+}
+
+// recordColorResults updates the stats of the players of game by the
+// colors they played, in games of all variants.
+func (bot *Bot) recordColorResults(game *othellogame.Game, winner *tgbotapi.User) {
+	white, black := game.WhiteUser().ID, game.BlackUser().ID
+	switch {
+	case winner == nil:
+		bot.db.IncrementWhiteDraws(white)
+		bot.db.IncrementBlackDraws(black)
+	case winner.ID == white:
+		bot.db.IncrementWhiteWins(white)
+		bot.db.IncrementBlackLosses(black)
+	default:
+		bot.db.IncrementWhiteLosses(white)
+		bot.db.IncrementBlackWins(black)
+	}
+}
@@ -9,6 +9,12 @@ const helpMsg = "Othello is a strategy board game for two players, " +
 	"is to have the majority of disks turned to display one's color " +
 	"when the last playable empty square is filled."
 
+const antiHelpMsg = "Anti-Othello is played like Othello, " +
+	"but the player with fewer disks at the end of the game wins."
+
+const holesHelpMsg = "Holes are blocked cells: no disk can be placed on them, " +
+	"and they break the lines of disks to turn over."
+
 const botPic = "https://cf.ltkcdn.net/boardgames/images/orig/224020-2123x1412-Othello.jpg"
 
 const (
@@ -16,6 +22,25 @@ const (
 	scoreboardButtonText = "🏆 Scoreboard"
 	profileButtonText    = "👤 Profile"
 	helpButtonText       = "❓ Help"
+	settingsButtonText   = "⚙️ Settings"
+	puzzleButtonText     = "🧩 Puzzle"
+
+	// endChatButtonText is followed by the name of the chat buddy.
+	endChatButtonText = "End chat with"
 )
 
+const settingsMsg = "⚙️ Settings\n\nTap a setting to change it."
+
 var resendQuery = "#Resend"
+
+// maxKeyboardBoardSize is the size of the largest board that fits in an
+// inline keyboard, which shows at most 8 buttons in a row.
+const maxKeyboardBoardSize = 8
+
+const emptyCellEmoji = "🟩"
+
+const (
+	maxHintsPerGame = 3
+	// hintDepth is how many moves ahead the engine searches for hints.
+	hintDepth = 4
+)
@@ -0,0 +1,277 @@
+package othellobot
+
+import (
+	"encoding/base64"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ArminGh02/othello-bot/pkg/i18n"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/holes"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const maxHandicap = 4
+
+// formatGameOptions formats options to be put at the end of callback data
+// as "<size>", "<size>:<variant>" or "<size>:<variant>:<start>", where the
+// start is "xot" for random openings, "h<corners>" for handicaps,
+// "o<pattern>" for holes, or "p<position>" for positions packed by
+// packPosition, which are "q<position>" if they have holes. The variant
+// of classic games with a start is left empty.
+func formatGameOptions(opts othellogame.Options) string {
+	size := opts.BoardSize
+	if opts.Start != nil {
+		size = opts.Start.Size()
+	}
+	res := strconv.Itoa(size)
+
+	start := ""
+	switch {
+	case opts.Start != nil && opts.Start.HasHoles():
+		start = "q" + packPosition(opts.Start, 4)
+	case opts.Start != nil:
+		start = "p" + packPosition(opts.Start, 3)
+	case opts.RandomOpening:
+		start = "xot"
+	case opts.Handicap > 0:
+		start = "h" + strconv.Itoa(opts.Handicap)
+	case opts.Holes != holes.None:
+		start = "o" + string(opts.Holes)
+	}
+
+	if opts.Variant != variant.Classic {
+		res += ":" + string(opts.Variant)
+	} else if start != "" {
+		// left out to fit positions into callback data
+		res += ":"
+	}
+	if start != "" {
+		res += ":" + start
+	}
+	return res
+}
+
+// formatJoinData formats the data of join buttons, which is "b" or "w" for
+// the color the creator of the game plays followed by the options of the
+// game, or just the options if the creator plays a random color.
+func formatJoinData(creatorColor cell.Cell, opts othellogame.Options) string {
+	switch creatorColor {
+	case cell.Black:
+		return "b" + formatGameOptions(opts)
+	case cell.White:
+		return "w" + formatGameOptions(opts)
+	default:
+		return formatGameOptions(opts)
+	}
+}
+
+func parseJoinData(s string) (cell.Cell, othellogame.Options) {
+	switch {
+	case strings.HasPrefix(s, "b"):
+		return cell.Black, parseGameOptions(s[1:])
+	case strings.HasPrefix(s, "w"):
+		return cell.White, parseGameOptions(s[1:])
+	default:
+		return cell.Empty, parseGameOptions(s)
+	}
+}
+
+// cutCreatorColor cuts the color the creator of a game with friends
+// chooses to play from the start of an inline query, like "black" in
+// "black handicap 2". The color is cell.Empty if it isn't chosen.
+func cutCreatorColor(query string) (cell.Cell, string) {
+	first, rest, _ := strings.Cut(strings.TrimSpace(query), " ")
+	switch strings.ToLower(first) {
+	case "black":
+		return cell.Black, rest
+	case "white":
+		return cell.White, rest
+	default:
+		return cell.Empty, query
+	}
+}
+
+// parseGameOptions parses the options at the end of callback data,
+// which are missing from the data of old messages.
+func parseGameOptions(s string) othellogame.Options {
+	res := othellogame.DefaultOptions()
+	fields := strings.SplitN(s, ":", 3)
+	if n, err := strconv.Atoi(fields[0]); err == nil && othellogame.IsValidBoardSize(n) {
+		res.BoardSize = n
+	}
+	if len(fields) > 1 {
+		res.Variant, _ = variant.Parse(fields[1])
+	}
+	if len(fields) > 2 {
+		start := fields[2]
+		switch {
+		case start == "xot":
+			res.RandomOpening = true
+		case strings.HasPrefix(start, "h"):
+			n, err := strconv.Atoi(start[1:])
+			if err == nil && n > 0 && n <= maxHandicap {
+				res.Handicap = n
+			}
+		case strings.HasPrefix(start, "o"):
+			res.Holes, _ = holes.Parse(start[1:])
+		case strings.HasPrefix(start, "p"):
+			res.Start, _ = unpackPosition(start[1:], res.BoardSize, 3)
+		case strings.HasPrefix(start, "q"):
+			res.Start, _ = unpackPosition(start[1:], res.BoardSize, 4)
+		}
+	}
+	return res
+}
+
+func gameOptionsLabel(opts othellogame.Options, lang i18n.Language) string {
+	size := opts.BoardSize
+	if opts.Start != nil {
+		size = opts.Start.Size()
+	}
+	res := lang.T(opts.Variant.Label()) + " " + sizeLabel(size)
+
+	switch {
+	case opts.Start != nil:
+		return lang.T("%s from a custom position", res)
+	case opts.RandomOpening:
+		return lang.T("%s with a random opening", res)
+	case opts.Handicap > 0:
+		return lang.T("%s with a %d corner handicap", res, opts.Handicap)
+	case opts.Holes != holes.None:
+		return lang.T("%s with %s", res, lang.T(opts.Holes.Label()))
+	}
+	return res
+}
+
+// packPosition packs a position into few enough characters to fit in
+// callback data, as a number with a digit per cell. The base is 3,
+// or 4 to also have a digit for holes.
+func packPosition(p *othellogame.Position, base int64) string {
+	b := big.NewInt(base)
+	n := big.NewInt(0)
+	if p.WhiteToMove {
+		n.SetInt64(1)
+	}
+	for y := len(p.Board) - 1; y >= 0; y-- {
+		for x := len(p.Board[y]) - 1; x >= 0; x-- {
+			n.Mul(n, b)
+			n.Add(n, big.NewInt(cellDigit(p.Board[y][x])))
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func unpackPosition(s string, size int, base int64) (*othellogame.Position, bool) {
+	packed, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+
+	b := big.NewInt(base)
+	n := new(big.Int).SetBytes(packed)
+	digit := new(big.Int)
+	res := &othellogame.Position{Board: make([][]cell.Cell, size)}
+	for y := range res.Board {
+		res.Board[y] = make([]cell.Cell, size)
+		for x := range res.Board[y] {
+			n.DivMod(n, b, digit)
+			res.Board[y][x] = digitCell(digit.Int64())
+		}
+	}
+	res.WhiteToMove = n.Sign() != 0
+	return res, true
+}
+
+func cellDigit(c cell.Cell) int64 {
+	switch c {
+	case cell.White:
+		return 1
+	case cell.Black:
+		return 2
+	case cell.Hole:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func digitCell(digit int64) cell.Cell {
+	switch digit {
+	case 1:
+		return cell.White
+	case 2:
+		return cell.Black
+	case 3:
+		return cell.Hole
+	default:
+		return cell.Empty
+	}
+}
+
+// inlineQueryGameOptions returns the options of the games offered for an
+// inline query: the standard games for an empty query, games with random
+// openings for "random" or "xot", games with a handicap for "handicap <n>",
+// games with holes for "holes" or "holes <pattern>", and games from
+// the position in the query otherwise.
+func inlineQueryGameOptions(query string) ([]othellogame.Options, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	forAllGames := func(modify func(opts *othellogame.Options)) []othellogame.Options {
+		res := make([]othellogame.Options, 0, len(variant.Variants)*len(othellogame.BoardSizes))
+		for _, v := range variant.Variants {
+			for _, size := range othellogame.BoardSizes {
+				opts := othellogame.Options{BoardSize: size, Variant: v}
+				modify(&opts)
+				res = append(res, opts)
+			}
+		}
+		return res
+	}
+
+	switch {
+	case query == "":
+		return forAllGames(func(*othellogame.Options) {}), nil
+	case query == "random" || query == "xot":
+		return forAllGames(func(opts *othellogame.Options) {
+			opts.RandomOpening = true
+		}), nil
+	case strings.HasPrefix(query, "handicap"):
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(query, "handicap")))
+		if err != nil || n < 1 || n > maxHandicap {
+			return nil, errInvalidGameQuery
+		}
+		return forAllGames(func(opts *othellogame.Options) {
+			opts.Handicap = n
+		}), nil
+	case strings.HasPrefix(query, "holes"):
+		pattern := holes.Random
+		if name := strings.TrimSpace(strings.TrimPrefix(query, "holes")); name != "" {
+			var ok bool
+			if pattern, ok = holes.Parse(name); !ok {
+				return nil, errInvalidGameQuery
+			}
+		}
+		return forAllGames(func(opts *othellogame.Options) {
+			opts.Holes = pattern
+		}), nil
+	}
+
+	start, err := othellogame.ParsePosition(query)
+	if err != nil {
+		return nil, errInvalidGameQuery
+	}
+	res := make([]othellogame.Options, 0, len(variant.Variants))
+	for _, v := range variant.Variants {
+		opts := othellogame.Options{BoardSize: start.Size(), Variant: v, Start: start}
+		game := othellogame.NewWithOptions(&tgbotapi.User{}, &tgbotapi.User{}, opts)
+		if game.IsEnded() {
+			return nil, errNoLegalMoves
+		}
+		res = append(res, opts)
+	}
+	return res, nil
+}
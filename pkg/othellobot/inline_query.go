@@ -2,8 +2,11 @@ package othellobot
 
 import (
 	"fmt"
-	"sync/atomic"
 
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/holes"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
 	"github.com/ArminGh02/othello-bot/pkg/util"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/google/uuid"
@@ -16,6 +19,7 @@ func (bot *Bot) handleInlineQuery(inlineQuery *tgbotapi.InlineQuery) {
 	}
 
 	user := inlineQuery.From
+	lang := bot.languageOf(user)
 
 	bot.userIDToCurrentGameMutex.Lock()
 	_, ok := bot.userIDToCurrentGame[user.ID]
@@ -25,37 +29,75 @@ func (bot *Bot) handleInlineQuery(inlineQuery *tgbotapi.InlineQuery) {
 			InlineQueryID:     inlineQuery.ID,
 			Results:           []interface{}{},
 			CacheTime:         0,
-			SwitchPMText:      "Can't play two games at the same time!",
+			SwitchPMText:      lang.T("Can't play two games at the same time!"),
 			SwitchPMParameter: "playingSimultaneously",
 		})
 		return
 	}
 
-	if bot.db.AddPlayer(user.ID, util.FullNameOf(user)) {
-		bot.scoreboard.Insert(bot.db.Find(user.ID))
-		atomic.AddUint64(&bot.usersJoinedToday, 1)
+	bot.ensurePlayer(user)
+
+	creatorColor, query := cutCreatorColor(inlineQuery.Query)
+	options, err := inlineQueryGameOptions(query)
+	if err != nil {
+		bot.api.Request(tgbotapi.InlineConfig{
+			InlineQueryID:     inlineQuery.ID,
+			Results:           []interface{}{},
+			CacheTime:         0,
+			SwitchPMText:      localizeError(lang, err),
+			SwitchPMParameter: "invalidGameQuery",
+		})
+		return
 	}
 
-	game := tgbotapi.NewInlineQueryResultArticleMarkdownV2(
-		uuid.NewString(),
-		"Othello",
-		fmt.Sprintf("Let's Play Othello\\! [🎯](%s)", botPic),
-	)
-	game.Description = helpMsg
-	game.ReplyMarkup = buildJoinToGameKeyboard()
-	game.ThumbURL = botPic
-	game.ThumbWidth = 330
-	game.ThumbHeight = 280
+	t := bot.themeOf(user.ID)
+	results := make([]interface{}, 0, len(options))
+	for _, opts := range options {
+		description := lang.T(helpMsg)
+		if opts.Variant == variant.Anti {
+			description = lang.T(antiHelpMsg)
+		}
+		if opts.Holes != holes.None || opts.Start != nil && opts.Start.HasHoles() {
+			description += "\n" + lang.T(holesHelpMsg)
+		}
+
+		title := gameOptionsLabel(opts, lang)
+		text := lang.T(
+			"Let's Play %s\\!",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdownV2, title),
+		) + fmt.Sprintf(" [🎯](%s)", botPic)
+		if creatorColor != cell.Empty {
+			title = lang.T("%s, you play %s", title, t.Emoji(creatorColor))
+			text += "\n" + tgbotapi.EscapeText(
+				tgbotapi.ModeMarkdownV2,
+				lang.T("%s plays %s", util.FirstNameElseLastName(user), t.Emoji(creatorColor)),
+			)
+		}
+
+		game := tgbotapi.NewInlineQueryResultArticleMarkdownV2(uuid.NewString(), title, text)
+		game.Description = description
+		game.ReplyMarkup = buildJoinToGameKeyboard(creatorColor, opts, lang)
+		game.ThumbURL = botPic
+		game.ThumbWidth = 330
+		game.ThumbHeight = 280
+
+		if opts.BoardSize == othellogame.DefaultBoardSize && opts.Variant == variant.Classic {
+			results = append([]interface{}{game}, results...)
+		} else {
+			results = append(results, game)
+		}
+	}
 
 	bot.api.Request(tgbotapi.InlineConfig{
 		InlineQueryID: inlineQuery.ID,
-		Results:       []interface{}{game},
+		Results:       results,
 		CacheTime:     0,
 	})
 }
 
 func (bot *Bot) resendGame(inlineQuery *tgbotapi.InlineQuery) {
 	user := inlineQuery.From
+	lang := bot.languageOf(user)
 
 	bot.userIDToCurrentGameMutex.Lock()
 	defer bot.userIDToCurrentGameMutex.Unlock()
@@ -66,23 +108,21 @@ func (bot *Bot) resendGame(inlineQuery *tgbotapi.InlineQuery) {
 			InlineQueryID:     inlineQuery.ID,
 			Results:           []interface{}{},
 			CacheTime:         0,
-			SwitchPMText:      errTooOldGame.Error(),
+			SwitchPMText:      localizeError(lang, errTooOldGame),
 			SwitchPMParameter: "oldGame",
 		})
 		return
 	}
 
-	msgText, replyMarkup := getRunningGameMsgAndReplyMarkup(
-		game,
-		bot.db.LegalMovesAreShown(game.ActiveUser().ID),
-		true,
-	)
+	v := bot.viewerOf(user)
+	msgText, replyMarkup := bot.runningGameRender(game, true)(v)
+	text, markup := textGameMessage(game, msgText, replyMarkup, v.theme)
 	msg := tgbotapi.NewInlineQueryResultArticle(
 		uuid.NewString(),
-		"Send down your current game",
-		msgText,
+		lang.T("Send down your current game"),
+		text,
 	)
-	msg.ReplyMarkup = replyMarkup
+	msg.ReplyMarkup = markup
 
 	bot.api.Request(tgbotapi.InlineConfig{
 		InlineQueryID: inlineQuery.ID,
@@ -0,0 +1,50 @@
+package othellobot
+
+import (
+	"errors"
+
+	"github.com/ArminGh02/othello-bot/pkg/i18n"
+	"github.com/ArminGh02/othello-bot/pkg/util"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// errPlayingAnotherGame is the error of starting a game with a player
+// who is playing another game.
+type errPlayingAnotherGame struct {
+	user *tgbotapi.User
+}
+
+func (e errPlayingAnotherGame) Error() string {
+	return util.FirstNameElseLastName(e.user) + " is playing another game"
+}
+
+// languageOf returns the language the user chose in the settings,
+// or the language of their Telegram app.
+func (bot *Bot) languageOf(user *tgbotapi.User) i18n.Language {
+	if lang, ok := i18n.Parse(bot.db.LanguageOf(user.ID)); ok {
+		return lang
+	}
+	return i18n.FromCode(user.LanguageCode)
+}
+
+// localizeError translates the message of err, which is the error of
+// a game or one of the errors of the bot.
+func localizeError(lang i18n.Language, err error) string {
+	var playing errPlayingAnotherGame
+	if errors.As(err, &playing) {
+		return lang.T("%s is playing another game", util.FirstNameElseLastName(playing.user))
+	}
+	return lang.T(err.Error())
+}
+
+// languageOfID returns the language of the user with the ID, whose
+// Telegram app language is known if they have played since the bot started.
+func (bot *Bot) languageOfID(userID int64) i18n.Language {
+	bot.userIDToUserMutex.Lock()
+	user, ok := bot.userIDToUser[userID]
+	bot.userIDToUserMutex.Unlock()
+	if !ok {
+		user = &tgbotapi.User{ID: userID}
+	}
+	return bot.languageOf(user)
+}
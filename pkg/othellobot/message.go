@@ -6,6 +6,7 @@ import (
 	"strings"
 	"sync/atomic"
 
+	"github.com/ArminGh02/othello-bot/pkg/i18n"
 	"github.com/ArminGh02/othello-bot/pkg/util"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -16,26 +17,32 @@ func (bot *Bot) handleMessage(message *tgbotapi.Message) {
 		return
 	}
 
-	if strings.HasPrefix(message.Text, "End chat with") {
+	lang := bot.languageOf(message.From)
+
+	if i18n.HasPrefix(message.Text, endChatButtonText) {
 		bot.userIDToChatBuddyMutex.Lock()
 		delete(bot.userIDToChatBuddy, message.From.ID)
 		bot.userIDToChatBuddyMutex.Unlock()
 
-		msg := tgbotapi.NewMessage(message.From.ID, "Chat ended.")
-		msg.ReplyMarkup = buildMainKeyboard()
+		msg := tgbotapi.NewMessage(message.From.ID, lang.T("Chat ended."))
+		msg.ReplyMarkup = buildMainKeyboard(lang)
 		bot.api.Send(msg)
 		return
 	}
 
-	switch message.Text {
-	case newGameButtonText:
+	switch text := message.Text; {
+	case i18n.Matches(text, newGameButtonText):
 		bot.askGameMode(message)
-	case scoreboardButtonText:
+	case i18n.Matches(text, scoreboardButtonText):
 		bot.showScoreboard(message)
-	case profileButtonText:
+	case i18n.Matches(text, profileButtonText):
 		bot.showProfile(message)
-	case helpButtonText:
+	case i18n.Matches(text, helpButtonText):
 		bot.showHelp(message)
+	case i18n.Matches(text, settingsButtonText):
+		bot.showSettings(message)
+	case i18n.Matches(text, puzzleButtonText):
+		bot.showPuzzle(message)
 	default:
 		user1 := message.From
 
@@ -46,30 +53,38 @@ func (bot *Bot) handleMessage(message *tgbotapi.Message) {
 			break
 		}
 
-		msg := fmt.Sprintf(
-			"📬 Message from %s:\n\n%s",
+		msg := bot.languageOf(user2).T(
+			"📬 Message from %s:",
 			util.FirstNameElseLastName(user1),
-			message.Text,
-		)
+		) + "\n\n" + message.Text
 		bot.api.Send(tgbotapi.NewMessage(user2.ID, msg))
 	}
 }
 
 func (bot *Bot) handleCommand(message *tgbotapi.Message) {
+	lang := bot.languageOf(message.From)
 	switch command := message.Command(); command {
 	case "start":
 		bot.handleStartCommand(message)
 	case "stats":
 		msgText := fmt.Sprintf("⚪️ Games played today: %d\n"+
 			"⚫️ Users joined today: %d\n"+
-			"🔴 All players: %d",
+			"🔴 All players: %d\n"+
+			"🧠 %s",
 			atomic.LoadUint64(&bot.gamesPlayedToday),
 			atomic.LoadUint64(&bot.usersJoinedToday),
 			bot.db.UsersCount(),
+			bot.memoryReport(),
 		)
 		bot.api.Send(tgbotapi.NewMessage(message.Chat.ID, msgText))
+	case "imageboard":
+		bot.toggleBoardIsImage(message)
+	case "confirmmoves":
+		bot.toggleConfirmsMoves(message)
+	case "tutorial":
+		bot.startTutorial(message)
 	default:
-		msgText := fmt.Sprintf("Sorry! %s is not recognized as a command.", command)
+		msgText := lang.T("Sorry! %s is not recognized as a command.", command)
 		bot.api.Send(tgbotapi.NewMessage(message.Chat.ID, msgText))
 	}
 }
@@ -77,31 +92,38 @@ func (bot *Bot) handleCommand(message *tgbotapi.Message) {
 func (bot *Bot) handleStartCommand(message *tgbotapi.Message) {
 	user := message.From
 
+	added := bot.ensurePlayer(user)
+
+	lang := bot.languageOf(user)
 	if arg := message.CommandArguments(); strings.HasPrefix(arg, "replay") {
 		if err := bot.sendGameReplay(user, arg); err != nil {
-			bot.api.Send(tgbotapi.NewMessage(user.ID, err.Error()))
+			bot.api.Send(tgbotapi.NewMessage(user.ID, localizeError(lang, err)))
 		}
 		return
 	}
 
-	msgText := fmt.Sprintf("Hi %s\\!\n"+
+	msgText := lang.T("Hi %s\\!\n"+
 		"I am *Othello Bot*\\.\n"+
 		"Have fun playing Othello strategic board game,\n"+
-		"with your friends or opponents around the world\\!", user.FirstName)
+		"with your friends or opponents around the world\\!",
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdownV2, user.FirstName))
 	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
-	msg.ReplyMarkup = buildMainKeyboard()
+	msg.ReplyMarkup = buildMainKeyboard(lang)
 	msg.ParseMode = "MarkdownV2"
 	bot.api.Send(msg)
 
-	if bot.db.AddPlayer(user.ID, util.FullNameOf(user)) {
-		bot.scoreboard.Insert(bot.db.Find(user.ID))
-		atomic.AddUint64(&bot.usersJoinedToday, 1)
+	if added {
+		msg := tgbotapi.NewMessage(message.Chat.ID, lang.T("New to othello? Learn it in a few moves!"))
+		msg.ReplyMarkup = buildTutorialKeyboard(0, false, bot.viewerOf(user))
+		bot.api.Send(msg)
 	}
 
 	log.Printf("Bot started by %v.", user)
 }
 
 func (bot *Bot) askGameMode(message *tgbotapi.Message) {
+	lang := bot.languageOf(message.From)
+
 	bot.userIDToCurrentGameMutex.Lock()
 	_, ok := bot.userIDToCurrentGame[message.From.ID]
 	bot.userIDToCurrentGameMutex.Unlock()
@@ -109,16 +131,16 @@ func (bot *Bot) askGameMode(message *tgbotapi.Message) {
 		bot.api.Send(
 			tgbotapi.NewMessage(
 				message.Chat.ID,
-				"You can't play more than one games at the same time.",
+				lang.T("You can't play more than one games at the same time."),
 			),
 		)
 		return
 	}
 
-	msgText := "You can play Othello with opponents around the world,\n" +
-		"or play with your friends in chats!"
+	msgText := lang.T("You can play Othello with opponents around the world,\n" +
+		"or play with your friends in chats!")
 	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
-	msg.ReplyMarkup = buildGameModeKeyboard()
+	msg.ReplyMarkup = buildGameModeKeyboard(lang)
 	_, err := bot.api.Send(msg)
 	if err != nil {
 		log.Panicln(err)
@@ -129,16 +151,52 @@ func (bot *Bot) showScoreboard(message *tgbotapi.Message) {
 	bot.api.Send(
 		tgbotapi.NewMessage(
 			message.Chat.ID,
-			bot.scoreboard.String(message.From.ID),
+			bot.scoreboard.String(message.From.ID, bot.languageOf(message.From)),
 		),
 	)
 }
 
 func (bot *Bot) showProfile(message *tgbotapi.Message) {
-	msg := bot.db.Find(message.From.ID).String(bot.scoreboard.RankOf(message.From.ID))
+	lang := bot.languageOf(message.From)
+	msg := profileText(bot.db.Find(message.From.ID), bot.scoreboard.RankOf(message.From.ID), lang)
 	bot.api.Send(tgbotapi.NewMessage(message.Chat.ID, msg))
 }
 
 func (bot *Bot) showHelp(message *tgbotapi.Message) {
-	bot.api.Send(tgbotapi.NewMessage(message.Chat.ID, helpMsg))
+	user := message.From
+	bot.ensurePlayer(user)
+
+	doc := bot.db.Find(user.ID)
+	v := bot.viewerOf(user)
+	msg := tgbotapi.NewMessage(message.Chat.ID, v.lang.T(helpMsg))
+	msg.ReplyMarkup = buildTutorialKeyboard(doc.TutorialStep, doc.TutorialCompleted, v)
+	bot.api.Send(msg)
+}
+
+func (bot *Bot) toggleBoardIsImage(message *tgbotapi.Message) {
+	user := message.From
+	bot.ensurePlayer(user)
+
+	bot.db.ToggleBoardIsImage(user.ID)
+
+	lang := bot.languageOf(user)
+	msgText := lang.T("Your next games will show the board as an emoji keyboard.")
+	if bot.db.BoardIsImage(user.ID) {
+		msgText = lang.T("Your next games with random opponents will show the board as a photo.")
+	}
+	bot.api.Send(tgbotapi.NewMessage(message.Chat.ID, msgText))
+}
+
+func (bot *Bot) toggleConfirmsMoves(message *tgbotapi.Message) {
+	user := message.From
+	bot.ensurePlayer(user)
+
+	bot.db.ToggleConfirmsMoves(user.ID)
+
+	lang := bot.languageOf(user)
+	msgText := lang.T("Your moves will be placed with a single tap.")
+	if bot.db.ConfirmsMoves(user.ID) {
+		msgText = lang.T("Your moves will be selected with the first tap and placed with a second tap on the same cell.")
+	}
+	bot.api.Send(tgbotapi.NewMessage(message.Chat.ID, msgText))
 }
@@ -0,0 +1,269 @@
+package othellobot
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ArminGh02/othello-bot/pkg/database"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/puzzle"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const puzzleDateLayout = "2006-01-02"
+
+// puzzleLeaderboardSize is the number of players on the puzzle leaderboard.
+const puzzleLeaderboardSize = 10
+
+type dailyPuzzle struct {
+	date       string
+	puzzle     *puzzle.Puzzle
+	difficulty int
+}
+
+// updateDailyPuzzle makes the puzzle of today in loc the daily puzzle. It's
+// the one saved for today already, or the next imported one, or else one
+// generated and saved.
+func (bot *Bot) updateDailyPuzzle(loc *time.Location) {
+	date := time.Now().In(loc).Format(puzzleDateLayout)
+	doc, found := bot.db.FindPuzzle(date)
+	if !found {
+		doc, found = bot.db.TakePuzzle(date)
+	}
+	if !found {
+		p := puzzle.Generate(rand.New(rand.NewSource(time.Now().UnixNano())))
+		doc = &database.PuzzleDoc{
+			Date:       date,
+			Position:   p.Position.String(),
+			Solution:   p.Solution,
+			Margin:     p.Margin,
+			NextBest:   p.NextBest,
+			Difficulty: p.Difficulty(),
+			Hash:       int64(p.Hash()),
+		}
+		bot.db.SavePuzzle(doc)
+	}
+
+	position, err := othellogame.ParsePosition(doc.Position)
+	if err != nil {
+		log.Panicln(err)
+	}
+	bot.dailyPuzzleMutex.Lock()
+	bot.dailyPuzzle = &dailyPuzzle{
+		date: date,
+		puzzle: &puzzle.Puzzle{
+			Position: position,
+			Solution: doc.Solution,
+			Margin:   doc.Margin,
+			NextBest: doc.NextBest,
+		},
+		difficulty: doc.Difficulty,
+	}
+	bot.dailyPuzzleMutex.Unlock()
+	log.Printf("Puzzle of %s is ready.", date)
+}
+
+func (bot *Bot) currentPuzzle() (*dailyPuzzle, bool) {
+	bot.dailyPuzzleMutex.Lock()
+	defer bot.dailyPuzzleMutex.Unlock()
+	return bot.dailyPuzzle, bot.dailyPuzzle != nil
+}
+
+func (bot *Bot) showPuzzle(message *tgbotapi.Message) {
+	user := message.From
+	bot.ensurePlayer(user)
+
+	v := bot.viewerOf(user)
+	daily, ok := bot.currentPuzzle()
+	if !ok {
+		bot.api.Send(tgbotapi.NewMessage(
+			message.Chat.ID,
+			v.lang.T("🧩 The puzzle of today isn't ready yet. Try again in a minute!"),
+		))
+		return
+	}
+
+	doc := bot.db.Find(user.ID)
+	if doc.LastPuzzleDate == daily.date {
+		msg := tgbotapi.NewMessage(
+			message.Chat.ID,
+			v.lang.T("You answered the puzzle of today already. The next one is at midnight!")+
+				"\n\n"+puzzleStatsText(doc, daily.date, v),
+		)
+		msg.ReplyMarkup = buildPuzzleLeaderboardKeyboard(v)
+		bot.api.Send(msg)
+		return
+	}
+
+	game := daily.puzzle.Game()
+	msgText := v.lang.T(
+		"🧩 Puzzle of %s\n%s to move. Find the move that wins by the most disks!",
+		daily.date,
+		v.theme.Emoji(game.ActiveCell()),
+	)
+	// the puzzles saved before they were rated have no difficulty
+	if daily.difficulty > 0 {
+		msgText += "\n" + v.lang.T("Difficulty: %s", strings.Repeat("⭐️", daily.difficulty))
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	msg.ReplyMarkup = buildPuzzleKeyboard(game, daily.date, true, v)
+	bot.api.Send(msg)
+}
+
+// answerPuzzle checks the move of the data "puzzle:<date>:<x>_<y>" and
+// counts it for the player unless it's illegal.
+func (bot *Bot) answerPuzzle(query *tgbotapi.CallbackQuery) {
+	user := query.From
+	// the puzzles are answered in groups by people who never started the bot
+	bot.ensurePlayer(user)
+	v := bot.viewerOf(user)
+
+	date, move := parsePuzzleData(strings.TrimPrefix(query.Data, "puzzle:"))
+	daily, ok := bot.currentPuzzle()
+	if !ok || daily.date != date {
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(
+			query.ID,
+			v.lang.T("This puzzle is over. Tap 🧩 Puzzle for the puzzle of today!"),
+		))
+		return
+	}
+
+	game := daily.puzzle.Game()
+	if !game.IsLegalMove(move) {
+		bot.api.Request(tgbotapi.NewCallback(query.ID, localizeError(v.lang, othellogame.ErrIllegalMove)))
+		return
+	}
+
+	doc := bot.db.Find(user.ID)
+	solved := move == daily.puzzle.Solution
+	streak := 0
+	if solved {
+		streak = 1
+		if doc.LastPuzzleDate == previousPuzzleDate(date) {
+			streak = doc.PuzzleStreak + 1
+		}
+	}
+	if !bot.db.RecordPuzzleAnswer(user.ID, date, solved, streak) {
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(
+			query.ID,
+			v.lang.T("You answered the puzzle of today already. The next one is at midnight!"),
+		))
+		return
+	}
+
+	mover := v.theme.Emoji(game.ActiveCell())
+	var msgText, answer string
+	if solved {
+		answer = v.lang.T("✅ Solved!")
+		msgText = answer + " " + v.lang.T("%s wins by %d disks with %s.", mover, daily.puzzle.Margin, move)
+	} else {
+		answer = v.lang.T("❌ Not the best move.")
+		msgText = answer + " " + v.lang.T(
+			"%s wins by %d disks with %s.",
+			mover,
+			daily.puzzle.Margin,
+			daily.puzzle.Solution,
+		)
+	}
+	msgText += "\n\n" + puzzleStatsText(bot.db.Find(user.ID), date, v)
+
+	game.PlaceDiskUnchecked(move)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(
+		query.Message.Chat.ID,
+		query.Message.MessageID,
+		msgText,
+		buildPuzzleKeyboard(game, date, false, v),
+	)
+	bot.api.Send(edit)
+	bot.api.Request(tgbotapi.NewCallback(query.ID, answer))
+
+	log.Printf("%v answered the puzzle of %s with %s.", user, date, move)
+}
+
+func (bot *Bot) showPuzzleLeaderboard(query *tgbotapi.CallbackQuery) {
+	user := query.From
+	bot.ensurePlayer(user)
+	v := bot.viewerOf(user)
+	daily, _ := bot.currentPuzzle()
+	today := ""
+	if daily != nil {
+		today = daily.date
+	}
+
+	var sb strings.Builder
+	sb.WriteString(v.lang.T("🏆 Puzzle leaderboard") + "\n\n")
+	for i, player := range bot.db.TopPuzzleSolvers(puzzleLeaderboardSize) {
+		name := player.Name
+		if player.HiddenFromScoreboard && player.UserID != user.ID {
+			name = v.lang.T("🕶 Hidden player")
+		}
+		sb.WriteString(v.lang.T(
+			"%d. %s: %d solved, 🔥 %d\n",
+			i+1,
+			name,
+			player.PuzzlesSolved,
+			currentPuzzleStreak(&player, today),
+		))
+	}
+	sb.WriteString("\n" + puzzleStatsText(bot.db.Find(user.ID), today, v))
+
+	bot.api.Send(tgbotapi.NewMessage(query.Message.Chat.ID, sb.String()))
+	bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
+}
+
+func puzzleStatsText(doc *database.PlayerDoc, today string, v viewer) string {
+	return v.lang.T(
+		"🧩 Solved %d of %d puzzles (%d%%)\n🔥 Streak: %d",
+		doc.PuzzlesSolved,
+		doc.PuzzlesTried,
+		doc.PuzzleSolveRate(),
+		currentPuzzleStreak(doc, today),
+	)
+}
+
+// currentPuzzleStreak returns the streak of the player, which is broken
+// if they didn't answer the puzzle of today or yesterday.
+func currentPuzzleStreak(doc *database.PlayerDoc, today string) int {
+	if doc.LastPuzzleDate != today && doc.LastPuzzleDate != previousPuzzleDate(today) {
+		return 0
+	}
+	return doc.PuzzleStreak
+}
+
+// previousPuzzleDate returns the date of the puzzle of the day before date.
+func previousPuzzleDate(date string) string {
+	t, err := time.Parse(puzzleDateLayout, date)
+	if err != nil {
+		return ""
+	}
+	return t.AddDate(0, 0, -1).Format(puzzleDateLayout)
+}
+
+func parsePuzzleData(data string) (date string, move coord.Coord) {
+	date, c, _ := strings.Cut(data, ":")
+	fmt.Sscanf(c, "%d_%d", &move.X, &move.Y)
+	return date, move
+}
+
+// buildPuzzleKeyboard returns the board of game with the legal moves
+// answering the puzzle of the date if answerable, or doing nothing if not.
+func buildPuzzleKeyboard(game *othellogame.Game, date string, answerable bool, v viewer) tgbotapi.InlineKeyboardMarkup {
+	keyboard := boardButtons(game, answerable, !answerable, v, func(x, y int) string {
+		if !answerable {
+			return "puzzleAnswered"
+		}
+		return fmt.Sprintf("puzzle:%s:%d_%d", date, x, y)
+	})
+	keyboard = append(keyboard, buildPuzzleLeaderboardKeyboard(v).InlineKeyboard...)
+	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+func buildPuzzleLeaderboardKeyboard(v viewer) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(v.lang.T("🏆 Puzzle leaderboard"), "puzzleLeaderboard"),
+	))
+}
@@ -0,0 +1,323 @@
+package othellobot
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/ArminGh02/othello-bot/pkg/database"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/holes"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+)
+
+// RetentionConfig controls how long the bot keeps data of finished games
+// and idle users in memory before the sweeper evicts it.
+type RetentionConfig struct {
+	GameDataTTL time.Duration
+	UserTTL     time.Duration
+	RematchTTL  time.Duration
+	SweepSpec   string
+}
+
+func DefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		GameDataTTL: 24 * time.Hour,
+		UserTTL:     24 * time.Hour,
+		RematchTTL:  10 * time.Minute,
+		SweepSpec:   "@every 10m",
+	}
+}
+
+// RetentionConfigFromEnv returns the default retention config overridden by
+// the OTHELLO_GAME_DATA_TTL, OTHELLO_USER_TTL, OTHELLO_REMATCH_TTL and
+// OTHELLO_SWEEP_SPEC environment variables, if they are set.
+func RetentionConfigFromEnv() (RetentionConfig, error) {
+	config := DefaultRetentionConfig()
+
+	durations := map[string]*time.Duration{
+		"OTHELLO_GAME_DATA_TTL": &config.GameDataTTL,
+		"OTHELLO_USER_TTL":      &config.UserTTL,
+		"OTHELLO_REMATCH_TTL":   &config.RematchTTL,
+	}
+	for name, duration := range durations {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*duration = d
+	}
+
+	if spec := os.Getenv("OTHELLO_SWEEP_SPEC"); spec != "" {
+		config.SweepSpec = spec
+	}
+	return config, nil
+}
+
+func (bot *Bot) storeGameData(game *othellogame.Game) {
+	data := newGameData(game)
+	positionHashes := make([]int64, len(game.PositionHashes()))
+	for i, hash := range game.PositionHashes() {
+		positionHashes[i] = int64(hash)
+	}
+	fingerprint := int64(game.Fingerprint())
+	duplicateOf, _ := bot.db.FindGameByFingerprint(fingerprint)
+
+	bot.gameIDToMovesSequenceMutex.Lock()
+	bot.gameIDToGameData[game.ID()] = data
+	bot.gameIDToMovesSequenceMutex.Unlock()
+
+	bot.db.SaveGame(&database.GameDoc{
+		GameID:          game.ID(),
+		MoveSequence:    data.moveSequence,
+		WhiteStarts:     data.whiteStarts,
+		BoardSize:       data.boardSize,
+		Variant:         string(data.variant),
+		StartPosition:   data.start.String(),
+		Handicap:        data.handicap,
+		RandomOpening:   data.randomOpening,
+		Holes:           string(data.holes),
+		WhitePlayerID:   data.whitePlayerID,
+		BlackPlayerID:   data.blackPlayerID,
+		WhitePlayerName: data.whitePlayerName,
+		BlackPlayerName: data.blackPlayerName,
+		WhiteScore:      data.whiteScore,
+		BlackScore:      data.blackScore,
+		WhiteHintsUsed:  data.whiteHintsUsed,
+		BlackHintsUsed:  data.blackHintsUsed,
+		FinishedAt:      data.finishedAt,
+		Opening:         data.opening,
+		PositionHashes:  positionHashes,
+		Fingerprint:     fingerprint,
+		DuplicateOf:     duplicateOf,
+	})
+	if duplicateOf == "" {
+		bot.countGameInPositions(game.PositionHashes())
+	}
+}
+
+// gamesWithPosition returns the number of games that reached the position
+// with the canonical hash. The counts are cached, since they are shown
+// after every move while the games are locked.
+func (bot *Bot) gamesWithPosition(hash uint64) int {
+	bot.positionToGamesCountMutex.Lock()
+	count, ok := bot.positionToGamesCount[hash]
+	bot.positionToGamesCountMutex.Unlock()
+	if ok {
+		return count
+	}
+
+	count = bot.db.CountGamesWithPosition(int64(hash))
+	bot.positionToGamesCountMutex.Lock()
+	bot.positionToGamesCount[hash] = count
+	bot.positionToGamesCountMutex.Unlock()
+	return count
+}
+
+// countGameInPositions adds a saved game to the cached counts of the
+// positions it reached.
+func (bot *Bot) countGameInPositions(hashes []uint64) {
+	bot.positionToGamesCountMutex.Lock()
+	defer bot.positionToGamesCountMutex.Unlock()
+
+	counted := make(map[uint64]bool, len(hashes))
+	for _, hash := range hashes {
+		if _, ok := bot.positionToGamesCount[hash]; ok && !counted[hash] {
+			bot.positionToGamesCount[hash]++
+		}
+		counted[hash] = true
+	}
+}
+
+// findGameData looks the game up in memory first and falls back to the
+// database for games that have already been evicted.
+func (bot *Bot) findGameData(gameID string) (gameData, bool) {
+	bot.gameIDToMovesSequenceMutex.Lock()
+	data, ok := bot.gameIDToGameData[gameID]
+	if ok {
+		replayFileIDs := make(map[string]string, len(data.replayFileIDs))
+		for key, fileID := range data.replayFileIDs {
+			replayFileIDs[key] = fileID
+		}
+		data.replayFileIDs = replayFileIDs
+	}
+	bot.gameIDToMovesSequenceMutex.Unlock()
+	if ok {
+		return data, true
+	}
+
+	doc, found := bot.db.FindGame(gameID)
+	if !found {
+		return gameData{}, false
+	}
+	replayFileIDs := doc.ReplayFileIDs
+	if replayFileIDs == nil {
+		replayFileIDs = make(map[string]string)
+	}
+	boardSize := doc.BoardSize
+	if boardSize == 0 {
+		// games saved before boards of other sizes
+		boardSize = othellogame.DefaultBoardSize
+	}
+	v, _ := variant.Parse(doc.Variant)
+	start, err := othellogame.ParsePosition(doc.StartPosition)
+	if err != nil {
+		// games saved before other start positions
+		start = othellogame.StandardPosition(boardSize, doc.WhiteStarts)
+	}
+	return gameData{
+		moveSequence:    doc.MoveSequence,
+		whiteStarts:     doc.WhiteStarts,
+		boardSize:       boardSize,
+		variant:         v,
+		start:           start,
+		handicap:        doc.Handicap,
+		randomOpening:   doc.RandomOpening,
+		holes:           holes.Pattern(doc.Holes),
+		whitePlayerID:   doc.WhitePlayerID,
+		blackPlayerID:   doc.BlackPlayerID,
+		whitePlayerName: doc.WhitePlayerName,
+		blackPlayerName: doc.BlackPlayerName,
+		whiteScore:      doc.WhiteScore,
+		blackScore:      doc.BlackScore,
+		whiteHintsUsed:  doc.WhiteHintsUsed,
+		blackHintsUsed:  doc.BlackHintsUsed,
+		opening:         doc.Opening,
+		finishedAt:      doc.FinishedAt,
+		replayFileIDs:   replayFileIDs,
+	}, true
+}
+
+// gameOptionsOf returns the options of a finished game, which rematches
+// are played with too. Rematches of random openings and holes draw them
+// again, rematches of games from a custom position start from the same
+// position, while handicap games give the handicap to the weaker player
+// again.
+func (bot *Bot) gameOptionsOf(gameID string) othellogame.Options {
+	data, ok := bot.findGameData(gameID)
+	if !ok {
+		return othellogame.DefaultOptions()
+	}
+
+	res := othellogame.Options{
+		BoardSize:     data.boardSize,
+		Variant:       data.variant,
+		Handicap:      data.handicap,
+		RandomOpening: data.randomOpening,
+		Holes:         data.holes,
+	}
+	if data.handicap == 0 && !data.randomOpening && data.holes == holes.None && !data.start.IsStandard() {
+		res.Start = data.start
+	}
+	return res
+}
+
+func (bot *Bot) cacheReplayFileID(gameID, key, fileID string) {
+	bot.gameIDToMovesSequenceMutex.Lock()
+	if data, ok := bot.gameIDToGameData[gameID]; ok {
+		data.replayFileIDs[key] = fileID
+	}
+	bot.gameIDToMovesSequenceMutex.Unlock()
+
+	bot.db.SetReplayFileID(gameID, key, fileID)
+}
+
+func (bot *Bot) sweep() {
+	now := time.Now()
+
+	evictedGames := 0
+	bot.gameIDToMovesSequenceMutex.Lock()
+	for gameID, data := range bot.gameIDToGameData {
+		if now.Sub(data.finishedAt) > bot.retention.GameDataTTL {
+			delete(bot.gameIDToGameData, gameID)
+			evictedGames++
+		}
+	}
+	bot.gameIDToMovesSequenceMutex.Unlock()
+
+	evictedRematches := 0
+	bot.userIDToRematchRequestMutex.Lock()
+	for userID, request := range bot.userIDToRematchRequest {
+		if now.Sub(request.requestedAt) > bot.retention.RematchTTL {
+			delete(bot.userIDToRematchRequest, userID)
+			evictedRematches++
+		}
+	}
+	bot.userIDToRematchRequestMutex.Unlock()
+
+	// the counts are fetched again for the positions still played
+	bot.positionToGamesCountMutex.Lock()
+	bot.positionToGamesCount = make(map[uint64]int)
+	bot.positionToGamesCountMutex.Unlock()
+
+	evictedUsers := bot.sweepIdleUsers(now)
+
+	log.Printf(
+		"Swept %d games, %d rematch requests and %d users. %s\n",
+		evictedGames,
+		evictedRematches,
+		evictedUsers,
+		bot.memoryReport(),
+	)
+}
+
+func (bot *Bot) sweepIdleUsers(now time.Time) (evicted int) {
+	bot.userIDToCurrentGameMutex.Lock()
+	defer bot.userIDToCurrentGameMutex.Unlock()
+
+	bot.userIDToLastTimeActiveMutex.Lock()
+	defer bot.userIDToLastTimeActiveMutex.Unlock()
+
+	for userID, lastTimeActive := range bot.userIDToLastTimeActive {
+		if _, playing := bot.userIDToCurrentGame[userID]; playing {
+			continue
+		}
+		if now.Sub(lastTimeActive) <= bot.retention.UserTTL {
+			continue
+		}
+
+		delete(bot.userIDToLastTimeActive, userID)
+
+		bot.userIDToUserMutex.Lock()
+		delete(bot.userIDToUser, userID)
+		bot.userIDToUserMutex.Unlock()
+
+		bot.userIDToChatBuddyMutex.Lock()
+		delete(bot.userIDToChatBuddy, userID)
+		bot.userIDToChatBuddyMutex.Unlock()
+
+		evicted++
+	}
+	return evicted
+}
+
+func (bot *Bot) memoryReport() string {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	bot.gameIDToMovesSequenceMutex.Lock()
+	gameDataCount := len(bot.gameIDToGameData)
+	bot.gameIDToMovesSequenceMutex.Unlock()
+
+	bot.userIDToUserMutex.Lock()
+	usersCount := len(bot.userIDToUser)
+	bot.userIDToUserMutex.Unlock()
+
+	bot.userIDToRematchRequestMutex.Lock()
+	rematchRequestsCount := len(bot.userIDToRematchRequest)
+	bot.userIDToRematchRequestMutex.Unlock()
+
+	return fmt.Sprintf(
+		"Heap: %.1f MiB | Finished games: %d | Users: %d | Rematch requests: %d",
+		float64(mem.HeapAlloc)/(1<<20),
+		gameDataCount,
+		usersCount,
+		rematchRequestsCount,
+	)
+}
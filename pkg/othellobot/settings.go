@@ -0,0 +1,203 @@
+package othellobot
+
+import (
+	"strings"
+
+	"github.com/ArminGh02/othello-bot/pkg/database"
+	"github.com/ArminGh02/othello-bot/pkg/gifmaker"
+	"github.com/ArminGh02/othello-bot/pkg/i18n"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const defaultMoveTimeLimit = 90
+
+// moveTimeLimits are the numbers of seconds players can choose to let
+// their opponents think before ending the game.
+var moveTimeLimits = [...]int{30, defaultMoveTimeLimit, 300}
+
+func (bot *Bot) showSettings(message *tgbotapi.Message) {
+	user := message.From
+	bot.ensurePlayer(user)
+
+	lang := bot.languageOf(user)
+	msg := tgbotapi.NewMessage(message.Chat.ID, lang.T(settingsMsg))
+	msg.ReplyMarkup = buildSettingsKeyboard(bot.db.Find(user.ID), lang)
+	bot.api.Send(msg)
+}
+
+// changeSetting changes the setting of the data "settings:<key>" by
+// toggling it, or choosing its next value, and shows the new settings.
+func (bot *Bot) changeSetting(query *tgbotapi.CallbackQuery) {
+	userID := query.From.ID
+	doc := bot.db.Find(userID)
+
+	key := strings.TrimPrefix(query.Data, "settings:")
+	switch key {
+	case "legalMoves":
+		bot.db.ToggleLegalMovesAreShown(userID)
+	case "boardImage":
+		bot.db.ToggleBoardIsImage(userID)
+	case "confirmMoves":
+		bot.db.ToggleConfirmsMoves(userID)
+	case "previewFlips":
+		bot.db.ToggleFlipsArePreviewed(userID)
+	case "replaySpeed":
+		bot.db.SetReplaySpeed(userID, string(nextReplaySpeed(replaySpeedOf(doc))))
+	case "silent":
+		bot.db.ToggleSilentGameMessages(userID)
+	case "rematches":
+		bot.db.ToggleRejectsRematches(userID)
+	case "hidden":
+		bot.db.ToggleHiddenFromScoreboard(userID)
+		bot.scoreboard.SetHidden(userID, !doc.HiddenFromScoreboard)
+	case "moveTimeLimit":
+		bot.db.SetMoveTimeLimit(userID, nextMoveTimeLimit(moveTimeLimitOf(doc)))
+	case "language":
+		bot.db.SetLanguage(userID, nextLanguage(doc.Language))
+	case "theme":
+		current, _ := theme.Parse(doc.Theme)
+		bot.db.SetTheme(userID, string(nextTheme(current)))
+	default:
+		bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
+		return
+	}
+
+	lang := bot.languageOf(query.From)
+	bot.api.Send(tgbotapi.NewEditMessageTextAndMarkup(
+		query.Message.Chat.ID,
+		query.Message.MessageID,
+		lang.T(settingsMsg),
+		buildSettingsKeyboard(bot.db.Find(userID), lang),
+	))
+	bot.api.Request(tgbotapi.NewCallback(query.ID, lang.T("Saved!")))
+
+	if key == "language" {
+		// the buttons of the main keyboard are in the old language
+		msg := tgbotapi.NewMessage(query.Message.Chat.ID, lang.T("The language is changed."))
+		msg.ReplyMarkup = buildMainKeyboard(lang)
+		bot.api.Send(msg)
+	}
+}
+
+func buildSettingsKeyboard(doc *database.PlayerDoc, lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
+	setting := func(label, value, key string) []tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(lang.T(label)+": "+value, "settings:"+key),
+		)
+	}
+	onOff := func(on bool) string {
+		if on {
+			return lang.T("On")
+		}
+		return lang.T("Off")
+	}
+
+	board := lang.T("⌨️ Keyboard")
+	if doc.BoardIsImage {
+		board = lang.T("🖼 Photo")
+	}
+	sound := lang.T("🔔 With sound")
+	if doc.SilentGameMessages {
+		sound = lang.T("🔕 Silent")
+	}
+	rematches := lang.T("Allowed")
+	if doc.RejectsRematches {
+		rematches = lang.T("Rejected")
+	}
+	scoreboard := lang.T("👁 Shown")
+	if doc.HiddenFromScoreboard {
+		scoreboard = lang.T("🕶 Hidden")
+	}
+	language := lang.T("Automatic")
+	if chosen, ok := i18n.Parse(doc.Language); ok {
+		language = chosen.Label()
+	}
+	t, _ := theme.Parse(doc.Theme)
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		setting("🌐 Language", language, "language"),
+		setting("🎨 Theme", lang.T(t.Label()), "theme"),
+		setting("Show legal moves", onOff(doc.LegalMovesAreShown), "legalMoves"),
+		setting("Board", board, "boardImage"),
+		setting("Confirm moves", onOff(doc.ConfirmsMoves), "confirmMoves"),
+		setting("Preview flips", onOff(doc.FlipsArePreviewed), "previewFlips"),
+		setting("Replay speed", lang.T(replaySpeedOf(doc).Label()), "replaySpeed"),
+		setting("Game messages", sound, "silent"),
+		setting("Rematch requests", rematches, "rematches"),
+		setting("Name on scoreboard", scoreboard, "hidden"),
+		setting("Time to move", lang.T("⏱ %d seconds", moveTimeLimitOf(doc)), "moveTimeLimit"),
+	)
+}
+
+// nextLanguage returns the language chosen after the language, going
+// through the languages with catalogs and back to the automatic one.
+func nextLanguage(language string) string {
+	for i, l := range i18n.Languages {
+		if string(l) == language {
+			if i+1 == len(i18n.Languages) {
+				return ""
+			}
+			return string(i18n.Languages[i+1])
+		}
+	}
+	return string(i18n.Languages[0])
+}
+
+// nextTheme returns the theme chosen after t, going through all of them.
+func nextTheme(t theme.Theme) theme.Theme {
+	for i, th := range theme.Themes {
+		if th == t {
+			return theme.Themes[(i+1)%len(theme.Themes)]
+		}
+	}
+	return theme.Themes[0]
+}
+
+// replaySpeedOf returns the speed the replays the player asks for are
+// sent with, unless another speed is chosen.
+func replaySpeedOf(doc *database.PlayerDoc) gifmaker.Speed {
+	for _, speed := range gifmaker.Speeds {
+		if string(speed) == doc.ReplaySpeed {
+			return speed
+		}
+	}
+	return gifmaker.DefaultOptions().Speed
+}
+
+func nextReplaySpeed(speed gifmaker.Speed) gifmaker.Speed {
+	for i, s := range gifmaker.Speeds {
+		if s == speed {
+			return gifmaker.Speeds[(i+1)%len(gifmaker.Speeds)]
+		}
+	}
+	return gifmaker.Speeds[0]
+}
+
+func moveTimeLimitOf(doc *database.PlayerDoc) int {
+	if doc.MoveTimeLimit <= 0 {
+		return defaultMoveTimeLimit
+	}
+	return doc.MoveTimeLimit
+}
+
+func nextMoveTimeLimit(seconds int) int {
+	for i, limit := range moveTimeLimits {
+		if limit == seconds {
+			return moveTimeLimits[(i+1)%len(moveTimeLimits)]
+		}
+	}
+	return moveTimeLimits[0]
+}
+
+// moveTimeLimitOfGame is the number of seconds a player of game may think
+// before the opponent can end it, which is the longer one the players chose.
+func (bot *Bot) moveTimeLimitOfGame(game *othellogame.Game) int {
+	white := moveTimeLimitOf(bot.db.Find(game.WhiteUser().ID))
+	black := moveTimeLimitOf(bot.db.Find(game.BlackUser().ID))
+	if white > black {
+		return white
+	}
+	return black
+}
@@ -0,0 +1,46 @@
+package othellobot
+
+import (
+	"github.com/ArminGh02/othello-bot/pkg/i18n"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// viewer has the preferences a game message is rendered with for
+// the user who sees it.
+type viewer struct {
+	lang  i18n.Language
+	theme theme.Theme
+	// user is nil for the messages everyone in a chat sees.
+	user *tgbotapi.User
+}
+
+func (bot *Bot) viewerOf(user *tgbotapi.User) viewer {
+	return viewer{lang: bot.languageOf(user), theme: bot.themeOf(user.ID), user: user}
+}
+
+// seesSelection reports whether the move the active player of game is
+// selecting is shown to the viewer, which it's hidden from the opponent.
+func (v viewer) seesSelection(game *othellogame.Game) bool {
+	return v.user == nil || v.user.ID == game.ActiveUser().ID
+}
+
+// viewerOfInlineMessage returns the viewer of the user who sent the
+// inline message, since everyone in the chat sees the same message.
+func (bot *Bot) viewerOfInlineMessage(inlineMessageID string) viewer {
+	bot.inlineMessageIDToUserMutex.Lock()
+	user, ok := bot.inlineMessageIDToUser[inlineMessageID]
+	bot.inlineMessageIDToUserMutex.Unlock()
+	if !ok {
+		return viewer{lang: i18n.English, theme: theme.Classic}
+	}
+	v := bot.viewerOf(user)
+	v.user = nil
+	return v
+}
+
+func (bot *Bot) themeOf(userID int64) theme.Theme {
+	t, _ := theme.Parse(bot.db.ThemeOf(userID))
+	return t
+}
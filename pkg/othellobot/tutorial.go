@@ -0,0 +1,208 @@
+package othellobot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// tutorialStep is a lesson of the tutorial, which is over when the player
+// makes a move it accepts in its position.
+type tutorialStep struct {
+	position       string
+	instruction    string
+	showLegalMoves bool
+	accepts        func(game *othellogame.Game, move coord.Coord) bool
+	// wrongMove is shown for the legal moves the step doesn't accept.
+	wrongMove string
+	// done is shown after the move, on the board it made.
+	done string
+}
+
+var tutorialSteps = [...]tutorialStep{
+	{
+		position: "--------/--------/--------/---wb---/---bw---/--------/--------/-------- b",
+		instruction: "You play black. Place a disk so that white disks are in a line " +
+			"between it and another black disk. Tap one of the cells marked for you.",
+		showLegalMoves: true,
+		accepts:        func(*othellogame.Game, coord.Coord) bool { return true },
+		done:           "The white disks between your disks are flipped to black!",
+	},
+	{
+		position: "-b------/-w------/----b---/----w---/--bw----/-----w--/------b-/-------- b",
+		instruction: "A disk flips the lines it closes in all directions at once. " +
+			"Find the move that flips white disks in three directions.",
+		accepts: func(game *othellogame.Game, move coord.Coord) bool {
+			return move == coord.New(4, 4)
+		},
+		wrongMove: "That move flips disks in only one direction. Look for another one!",
+		done:      "Three directions at once!",
+	},
+	{
+		position: "-wb-----/--------/--------/--------/--------/--------/--------/-----wwb b",
+		instruction: "Players who can't flip any disk must pass. " +
+			"Make the move that leaves white without any move.",
+		accepts: func(game *othellogame.Game, move coord.Coord) bool {
+			return game.Clone().PlaceDiskUnchecked(move)
+		},
+		wrongMove: "After that move white could still play. Try another one!",
+		done: "White has no moves and passes, so it's your turn again. " +
+			"When neither player can move, the game is over.",
+	},
+	{
+		position: "-wb-----/--------/--------/---wb---/---bw---/--------/--------/-------- b",
+		instruction: "Disks on the corners can never be flipped, " +
+			"since no line goes through a corner. Take the corner!",
+		accepts: func(game *othellogame.Game, move coord.Coord) bool {
+			last := game.Size() - 1
+			return (move.X == 0 || move.X == last) && (move.Y == 0 || move.Y == last)
+		},
+		wrongMove: "That isn't a corner. Look at the edges of the board!",
+		done:      "This corner is yours for the rest of the game.",
+	},
+	{
+		position: "bbw-----/--------/--------/---wb---/---bw---/--------/--------/-------- b",
+		instruction: "Disks in a full line along the edge from your corner can never " +
+			"be flipped either: they are stable. Make your line on the edge longer.",
+		accepts: func(game *othellogame.Game, move coord.Coord) bool {
+			return move == coord.New(3, 0)
+		},
+		wrongMove: "That disk could be flipped back. Play next to your disks on the edge!",
+		done: "These four disks are stable. Collecting stable disks " +
+			"is how games of othello are won!",
+	},
+}
+
+func (bot *Bot) startTutorial(message *tgbotapi.Message) {
+	user := message.From
+	bot.ensurePlayer(user)
+
+	step := bot.db.Find(user.ID).TutorialStep
+	text, markup := tutorialStepMsg(step, bot.viewerOf(user))
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyMarkup = markup
+	bot.api.Send(msg)
+}
+
+// handleTutorial shows the step of the data "tutorial:<step>", or checks
+// the move of the data "tutorial:<step>:<x>_<y>" for it.
+func (bot *Bot) handleTutorial(query *tgbotapi.CallbackQuery) {
+	user := query.From
+	bot.ensurePlayer(user)
+	v := bot.viewerOf(user)
+
+	data := strings.TrimPrefix(query.Data, "tutorial:")
+	stepData, moveData, isMove := strings.Cut(data, ":")
+	step, err := strconv.Atoi(stepData)
+	if err != nil || step < 0 || step > len(tutorialSteps) {
+		bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
+		return
+	}
+
+	if !isMove {
+		if step == len(tutorialSteps) {
+			bot.finishTutorial(query, v)
+			return
+		}
+		bot.db.SetTutorialStep(user.ID, step)
+		text, markup := tutorialStepMsg(step, v)
+		bot.api.Send(tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup))
+		bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
+		return
+	}
+	if step == len(tutorialSteps) {
+		bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
+		return
+	}
+
+	var move coord.Coord
+	fmt.Sscanf(moveData, "%d_%d", &move.X, &move.Y)
+	s := &tutorialSteps[step]
+	game := tutorialGame(s)
+	switch {
+	case !game.IsLegalMove(move):
+		bot.api.Request(tgbotapi.NewCallback(query.ID, localizeError(v.lang, othellogame.ErrIllegalMove)))
+		return
+	case !s.accepts(game, move):
+		bot.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, v.lang.T(s.wrongMove)))
+		return
+	}
+
+	game.PlaceDiskUnchecked(move)
+	bot.db.SetTutorialStep(user.ID, step+1)
+
+	next := tgbotapi.NewInlineKeyboardButtonData(v.lang.T("Next ▶️"), fmt.Sprintf("tutorial:%d", step+1))
+	if step+1 == len(tutorialSteps) {
+		next = tgbotapi.NewInlineKeyboardButtonData(v.lang.T("🏁 Finish"), fmt.Sprintf("tutorial:%d", step+1))
+	}
+	keyboard := boardButtons(game, false, true, v, func(x, y int) string {
+		return fmt.Sprintf("tutorial:%d:%d_%d", len(tutorialSteps), x, y)
+	})
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(next))
+	text := tutorialTitle(step, v) + "\n\n✅ " + v.lang.T(s.done)
+	bot.api.Send(tgbotapi.NewEditMessageTextAndMarkup(
+		query.Message.Chat.ID,
+		query.Message.MessageID,
+		text,
+		tgbotapi.NewInlineKeyboardMarkup(keyboard...),
+	))
+	bot.api.Request(tgbotapi.NewCallback(query.ID, v.lang.T("Well done!")))
+}
+
+func (bot *Bot) finishTutorial(query *tgbotapi.CallbackQuery, v viewer) {
+	bot.db.CompleteTutorial(query.From.ID)
+	bot.api.Send(tgbotapi.NewEditMessageText(
+		query.Message.Chat.ID,
+		query.Message.MessageID,
+		v.lang.T("🎓 You completed the tutorial! Tap %s to play your first game.", v.lang.T(newGameButtonText)),
+	))
+	bot.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: query.ID})
+
+	log.Printf("%v completed the tutorial.", query.From)
+}
+
+func tutorialGame(s *tutorialStep) *othellogame.Game {
+	start, err := othellogame.ParsePosition(s.position)
+	if err != nil {
+		log.Panicln(err)
+	}
+	return othellogame.NewWithOptions(nil, nil, othellogame.Options{Start: start})
+}
+
+func tutorialTitle(step int, v viewer) string {
+	return v.lang.T("🎓 Tutorial %d/%d", step+1, len(tutorialSteps))
+}
+
+func tutorialStepMsg(step int, v viewer) (string, tgbotapi.InlineKeyboardMarkup) {
+	if step >= len(tutorialSteps) {
+		// the tutorial is started again by the players who completed it
+		step = 0
+	}
+	s := &tutorialSteps[step]
+	keyboard := boardButtons(tutorialGame(s), s.showLegalMoves, false, v, func(x, y int) string {
+		return fmt.Sprintf("tutorial:%d:%d_%d", step, x, y)
+	})
+	return tutorialTitle(step, v) + "\n\n" + v.lang.T(s.instruction), tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+// buildTutorialKeyboard returns the button that starts the tutorial, or
+// continues it from the step the player is on.
+func buildTutorialKeyboard(step int, completed bool, v viewer) tgbotapi.InlineKeyboardMarkup {
+	text := v.lang.T("🎓 Tutorial")
+	if completed {
+		text = v.lang.T("🎓 Tutorial ✅")
+	}
+	if step > 0 && step < len(tutorialSteps) {
+		text = v.lang.T("🎓 Continue the tutorial (%d/%d)", step+1, len(tutorialSteps))
+	} else {
+		step = 0
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("tutorial:%d", step)),
+	))
+}
@@ -2,12 +2,24 @@ package othellobot
 
 import (
 	"fmt"
-	"math"
+	"log"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/ArminGh02/othello-bot/pkg/consts"
+	"github.com/ArminGh02/othello-bot/pkg/database"
+	"github.com/ArminGh02/othello-bot/pkg/engine"
+	"github.com/ArminGh02/othello-bot/pkg/gifmaker"
+	"github.com/ArminGh02/othello-bot/pkg/i18n"
 	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/holes"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
 	"github.com/ArminGh02/othello-bot/pkg/util"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -15,40 +27,173 @@ func newGameData(game *othellogame.Game) gameData {
 	return gameData{
 		moveSequence:    game.MovesSequence(),
 		whiteStarts:     game.WhiteStarted(),
+		boardSize:       game.Size(),
+		variant:         game.Variant(),
+		start:           game.StartPosition(),
+		handicap:        game.Handicap(),
+		randomOpening:   game.IsRandomOpening(),
+		holes:           game.Holes(),
+		whitePlayerID:   game.WhiteUser().ID,
+		blackPlayerID:   game.BlackUser().ID,
 		whitePlayerName: util.FirstNameElseLastName(game.WhiteUser()),
 		blackPlayerName: util.FirstNameElseLastName(game.BlackUser()),
 		whiteScore:      game.WhiteDisks(),
 		blackScore:      game.BlackDisks(),
+		whiteHintsUsed:  game.HintsUsed(game.WhiteUser()),
+		blackHintsUsed:  game.HintsUsed(game.BlackUser()),
+		opening:         openingOf(game),
+		finishedAt:      time.Now(),
+		replayFileIDs:   make(map[string]string),
 	}
 }
 
+// openingOf returns the name of the last opening of the book game
+// has passed through, if any.
+func openingOf(game *othellogame.Game) string {
+	name, _ := engine.DefaultBook().Opening(game)
+	return name
+}
+
+// gameMarkup builds the reply markup of a game message. Compact markups
+// replace the emoji board with buttons of the legal moves, for messages
+// that show the board as a photo.
+type gameMarkup func(compact bool) *tgbotapi.InlineKeyboardMarkup
+
+// gameRender renders a game message in the language and the theme of its viewer.
+type gameRender func(v viewer) (msg string, replyMarkup gameMarkup)
+
+type gameMessage struct {
+	id      int
+	isPhoto bool
+}
+
 func (bot *Bot) sendEditMessageTextForGame(
-	msgText string,
-	replyMarkup *tgbotapi.InlineKeyboardMarkup,
-	user1, user2 *tgbotapi.User,
+	game *othellogame.Game,
+	render gameRender,
 	inlineMessageID string,
 ) {
 	if inlineMessageID != "" {
+		v := bot.viewerOfInlineMessage(inlineMessageID)
+		msgText, replyMarkup := render(v)
+		text, markup := textGameMessage(game, msgText, replyMarkup, v.theme)
 		bot.api.Send(tgbotapi.EditMessageTextConfig{
 			BaseEdit: tgbotapi.BaseEdit{
 				InlineMessageID: inlineMessageID,
-				ReplyMarkup:     replyMarkup,
+				ReplyMarkup:     markup,
 			},
-			Text: msgText,
+			Text: text,
 		})
 		return
 	}
 
-	bot.userIDToMessageIDMutex.Lock()
-	messageID1 := bot.userIDToMessageID[user1.ID]
-	messageID2 := bot.userIDToMessageID[user2.ID]
-	bot.userIDToMessageIDMutex.Unlock()
+	// the photos are reused for the players who see the same board
+	type photoKey struct {
+		theme         theme.Theme
+		showSelection bool
+	}
+	photos := make(map[photoKey]tgbotapi.RequestFileData)
+	for _, user := range [...]*tgbotapi.User{game.WhiteUser(), game.BlackUser()} {
+		bot.userIDToGameMessageMutex.Lock()
+		message := bot.userIDToGameMessage[user.ID]
+		bot.userIDToGameMessageMutex.Unlock()
+
+		v := bot.viewerOf(user)
+		msgText, replyMarkup := render(v)
+		if !message.isPhoto {
+			text, markup := textGameMessage(game, msgText, replyMarkup, v.theme)
+			bot.api.Send(tgbotapi.NewEditMessageTextAndMarkup(user.ID, message.id, text, *markup))
+			continue
+		}
+
+		key := photoKey{v.theme, v.seesSelection(game)}
+		photo, ok := photos[key]
+		if !ok {
+			var err error
+			if photo, err = boardPhoto(game, v); err != nil {
+				// the board is shown in the caption and the keyboard instead,
+				// as the text of a photo message can't be edited
+				log.Println("Error rendering board:", err)
+				text, markup := textGameMessage(game, msgText, replyMarkup, v.theme)
+				edit := tgbotapi.NewEditMessageCaption(user.ID, message.id, text)
+				edit.ReplyMarkup = markup
+				bot.api.Send(edit)
+				continue
+			}
+		}
+		media := tgbotapi.NewInputMediaPhoto(photo)
+		media.Caption = msgText
+		sent, err := bot.api.Send(tgbotapi.EditMessageMediaConfig{
+			BaseEdit: tgbotapi.BaseEdit{
+				ChatID:      user.ID,
+				MessageID:   message.id,
+				ReplyMarkup: replyMarkup(true),
+			},
+			Media: media,
+		})
+		if err == nil && len(sent.Photo) > 0 {
+			photos[key] = tgbotapi.FileID(sent.Photo[len(sent.Photo)-1].FileID)
+		}
+	}
+}
+
+// textGameMessage returns the text and the markup of a game message without
+// a photo. Boards too large for the emoji keyboard are shown in the text.
+func textGameMessage(
+	game *othellogame.Game,
+	msgText string,
+	replyMarkup gameMarkup,
+	t theme.Theme,
+) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if fitsKeyboard(game) {
+		return msgText, replyMarkup(false)
+	}
+	return boardText(game, t) + "\n\n" + msgText, replyMarkup(true)
+}
+
+func fitsKeyboard(game *othellogame.Game) bool {
+	return game.Size() <= maxKeyboardBoardSize
+}
+
+func boardText(game *othellogame.Game, t theme.Theme) string {
+	var b strings.Builder
+	for y, row := range game.Board() {
+		for _, c := range row {
+			if c == cell.Empty {
+				b.WriteString(emptyCellEmoji)
+			} else {
+				b.WriteString(t.Emoji(c))
+			}
+		}
+		fmt.Fprintf(&b, " %d\n", y+1)
+	}
+	// fullwidth letters, about as wide as the emojis
+	for x := 0; x < game.Size(); x++ {
+		b.WriteRune('ａ' + rune(x))
+	}
+	return b.String()
+}
+
+func sizeLabel(size int) string {
+	return fmt.Sprintf("%d×%d", size, size)
+}
 
-	msg1 := tgbotapi.NewEditMessageTextAndMarkup(user1.ID, messageID1, msgText, *replyMarkup)
-	msg2 := tgbotapi.NewEditMessageTextAndMarkup(user2.ID, messageID2, msgText, *replyMarkup)
+func boardPhoto(game *othellogame.Game, v viewer) (tgbotapi.RequestFileData, error) {
+	snapshot, err := gifmaker.Snapshot(game, v.theme, v.seesSelection(game))
+	if err != nil {
+		return nil, err
+	}
+	return tgbotapi.FileBytes{Name: game.ID() + ".png", Bytes: snapshot}, nil
+}
 
-	bot.api.Send(msg1)
-	bot.api.Send(msg2)
+// ensurePlayer adds user to the players and the scoreboard if they are
+// new, and reports whether they were.
+func (bot *Bot) ensurePlayer(user *tgbotapi.User) (added bool) {
+	if !bot.db.AddPlayer(user.ID, util.FullNameOf(user)) {
+		return false
+	}
+	bot.scoreboard.Insert(bot.db.Find(user.ID))
+	atomic.AddUint64(&bot.usersJoinedToday, 1)
+	return true
 }
 
 func (bot *Bot) opponentOf(user *tgbotapi.User) (*tgbotapi.User, error) {
@@ -62,41 +207,152 @@ func (bot *Bot) opponentOf(user *tgbotapi.User) (*tgbotapi.User, error) {
 	return game.OpponentOf(user), nil
 }
 
+// runningGameRender renders the message of a running game with the
+// settings of its active player.
+func (bot *Bot) runningGameRender(game *othellogame.Game, inline bool) gameRender {
+	player := bot.db.Find(game.ActiveUser().ID)
+	previousGames := 0
+	if len(game.MovesSequence()) > 0 {
+		previousGames = bot.gamesWithPosition(game.CanonicalHash())
+	}
+	return func(v viewer) (string, gameMarkup) {
+		return getRunningGameMsgAndReplyMarkup(
+			game,
+			player.LegalMovesAreShown,
+			player.FlipsArePreviewed,
+			inline,
+			previousGames,
+			v,
+		)
+	}
+}
+
+// profileText is the profile of the player of doc, who is ranked rank on
+// the scoreboard.
+func profileText(doc *database.PlayerDoc, rank int, lang i18n.Language) string {
+	res := lang.T(
+		"%s's Profile:\nRank: %d\nWins: %d\nLosses: %d\nDraws: %d\nWin Percentage: %d%%",
+		doc.Name,
+		rank,
+		doc.Wins,
+		doc.Losses,
+		doc.Draws,
+		doc.WinRate(),
+	)
+	if doc.AntiWins+doc.AntiLosses+doc.AntiDraws > 0 {
+		res += lang.T(
+			"\n\nAnti-Othello:\nWins: %d\nLosses: %d\nDraws: %d",
+			doc.AntiWins,
+			doc.AntiLosses,
+			doc.AntiDraws,
+		)
+	}
+	if whiteGames, blackGames := doc.WhiteGames(), doc.BlackGames(); whiteGames+blackGames > 0 {
+		res += lang.T(
+			"\n\nWins as White: %d%% of %d\nWins as Black: %d%% of %d",
+			doc.WhiteWinRate(),
+			whiteGames,
+			doc.BlackWinRate(),
+			blackGames,
+		)
+	}
+	if doc.PuzzlesTried > 0 {
+		res += lang.T(
+			"\n\nPuzzles solved: %d of %d (%d%%)",
+			doc.PuzzlesSolved,
+			doc.PuzzlesTried,
+			doc.PuzzleSolveRate(),
+		)
+	}
+	return res
+}
+
+// passText tells that the opponent of the active player of game had no
+// legal moves and passed.
+func passText(lang i18n.Language, t theme.Theme, game *othellogame.Game) string {
+	return lang.T("%s has no moves — pass", t.Emoji(game.ActiveCell().Reversed()))
+}
+
 func getRunningGameMsgAndReplyMarkup(
 	game *othellogame.Game,
-	showLegalMoves, inline bool,
-) (msg string, replyMarkup *tgbotapi.InlineKeyboardMarkup) {
-	msg = fmt.Sprintf(
+	showLegalMoves, previewFlips, inline bool,
+	previousGames int,
+	v viewer,
+) (msg string, replyMarkup gameMarkup) {
+	lang := v.lang
+	if game.Variant() == variant.Anti {
+		msg = lang.T("🙃 Anti-Othello: the player with fewer disks wins!") + "\n"
+	}
+	msg += lang.T(
 		"Turn of: %s%s\n%s%s: %d\n%s%s: %d\nDon't count your chickens before they hatch!",
-		game.ActiveColor(),
+		v.theme.Emoji(game.ActiveCell()),
 		util.FirstNameElseLastName(game.ActiveUser()),
-		consts.WhiteDiskEmoji,
+		v.theme.Emoji(cell.White),
 		util.FirstNameElseLastName(game.WhiteUser()),
 		game.WhiteDisks(),
-		consts.BlackDiskEmoji,
+		v.theme.Emoji(cell.Black),
 		util.FirstNameElseLastName(game.BlackUser()),
 		game.BlackDisks(),
 	)
-	return msg, buildGameKeyboard(game, showLegalMoves, inline)
+	if game.LastTurnPassed() {
+		msg += "\n" + passText(lang, v.theme, game)
+	}
+	if opening, inBook := engine.DefaultBook().Opening(game); inBook && opening != "" {
+		msg += "\n" + lang.T("📖 Opening: %s", opening)
+	}
+	if previousGames > 0 {
+		msg += "\n" + lang.T("📊 Previous games with this position: %d", previousGames)
+	}
+	return msg, func(compact bool) *tgbotapi.InlineKeyboardMarkup {
+		return buildGameKeyboard(game, showLegalMoves, previewFlips, inline, compact, v)
+	}
+}
+
+func gameOverRender(game *othellogame.Game, botUsername string, inline bool) gameRender {
+	return func(v viewer) (string, gameMarkup) {
+		return getGameOverMsgAndReplyMarkup(game, botUsername, inline, v)
+	}
 }
 
 func getGameOverMsgAndReplyMarkup(
 	game *othellogame.Game,
 	botUsername string,
 	inline bool,
-) (msg string, replyMarkup *tgbotapi.InlineKeyboardMarkup) {
+	v viewer,
+) (msg string, replyMarkup gameMarkup) {
+	lang := v.lang
 	if winner := game.Winner(); winner == nil {
-		msg = "Draw"
+		msg = lang.T("Draw")
 	} else {
-		msg = fmt.Sprintf(
+		winnerDisks, loserDisks := game.WhiteDisks(), game.BlackDisks()
+		if *winner == *game.BlackUser() {
+			winnerDisks, loserDisks = loserDisks, winnerDisks
+		}
+		msg = lang.T(
 			"%s%s WON! %d to %d! 🔥",
-			game.WinnerColor(),
+			v.theme.Emoji(game.WinnerCell()),
 			util.FirstNameElseLastName(winner),
-			int(math.Max(float64(game.WhiteDisks()), float64(game.BlackDisks()))),
-			int(math.Min(float64(game.WhiteDisks()), float64(game.BlackDisks()))),
+			winnerDisks,
+			loserDisks,
 		)
 	}
-	return msg, buildGameOverKeyboard(game, botUsername, inline)
+	if game.Variant() == variant.Anti {
+		msg = lang.T("🙃 Anti-Othello") + "\n" + msg
+	}
+	return msg, func(compact bool) *tgbotapi.InlineKeyboardMarkup {
+		return buildGameOverKeyboard(game, botUsername, inline, compact, v)
+	}
+}
+
+func surrenderRender(
+	game *othellogame.Game,
+	winner, loser *tgbotapi.User,
+	botUsername string,
+	inline bool,
+) gameRender {
+	return func(v viewer) (string, gameMarkup) {
+		return getSurrenderMsgAndReplyMarkup(game, winner, loser, botUsername, inline, v)
+	}
 }
 
 func getSurrenderMsgAndReplyMarkup(
@@ -104,13 +360,27 @@ func getSurrenderMsgAndReplyMarkup(
 	winner, loser *tgbotapi.User,
 	botUsername string,
 	inline bool,
-) (msg string, replyMarkup *tgbotapi.InlineKeyboardMarkup) {
-	msg = fmt.Sprintf(
+	v viewer,
+) (msg string, replyMarkup gameMarkup) {
+	msg = v.lang.T(
 		"%s surrendered to %s!",
 		util.FirstNameElseLastName(loser),
 		util.FirstNameElseLastName(winner),
 	)
-	return msg, buildGameOverKeyboard(game, botUsername, inline)
+	return msg, func(compact bool) *tgbotapi.InlineKeyboardMarkup {
+		return buildGameOverKeyboard(game, botUsername, inline, compact, v)
+	}
+}
+
+func earlyEndRender(
+	game *othellogame.Game,
+	loser *tgbotapi.User,
+	botUsername string,
+	inline bool,
+) gameRender {
+	return func(v viewer) (string, gameMarkup) {
+		return getEarlyEndMsgAndReplyMarkup(game, loser, botUsername, inline, v)
+	}
 }
 
 func getEarlyEndMsgAndReplyMarkup(
@@ -118,31 +388,36 @@ func getEarlyEndMsgAndReplyMarkup(
 	loser *tgbotapi.User,
 	botUsername string,
 	inline bool,
-) (msg string, replyMarkup *tgbotapi.InlineKeyboardMarkup) {
-	msg = fmt.Sprintf(
+	v viewer,
+) (msg string, replyMarkup gameMarkup) {
+	msg = v.lang.T(
 		"Game ended due to inactivity of %s.",
 		util.FirstNameElseLastName(loser),
 	)
-	return msg, buildGameOverKeyboard(game, botUsername, inline)
+	return msg, func(compact bool) *tgbotapi.InlineKeyboardMarkup {
+		return buildGameOverKeyboard(game, botUsername, inline, compact, v)
+	}
 }
 
 func buildGameKeyboard(
 	game *othellogame.Game,
-	showLegalMoves, inline bool,
+	showLegalMoves, previewFlips, inline, compact bool,
+	v viewer,
 ) *tgbotapi.InlineKeyboardMarkup {
+	lang := v.lang
 	var button1 tgbotapi.InlineKeyboardButton
 	if inline {
 		button1 = tgbotapi.InlineKeyboardButton{
-			Text:                         "🔽 Send down",
+			Text:                         lang.T("🔽 Send down"),
 			SwitchInlineQueryCurrentChat: &resendQuery,
 		}
 	} else {
-		button1 = tgbotapi.NewInlineKeyboardButtonData("💬 Chat", "chat")
+		button1 = tgbotapi.NewInlineKeyboardButtonData(lang.T("💬 Chat"), "chat")
 	}
 
-	button2text := "Show legal moves"
+	button2text := lang.T("Show legal moves")
 	if showLegalMoves {
-		button2text = "Hide legal moves"
+		button2text = lang.T("Hide legal moves")
 	}
 
 	row2 := tgbotapi.NewInlineKeyboardRow(
@@ -151,26 +426,73 @@ func buildGameKeyboard(
 	)
 
 	row3 := tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🔚 End", "end"),
-		tgbotapi.NewInlineKeyboardButtonData("🏳️ Surrender", "surrender"),
+		tgbotapi.NewInlineKeyboardButtonData(lang.T("🔚 End"), "end"),
+		tgbotapi.NewInlineKeyboardButtonData(lang.T("🏳️ Surrender"), "surrender"),
+	)
+
+	previewText := lang.T("Preview flips")
+	if previewFlips {
+		previewText = lang.T("Don't preview flips")
+	}
+	row4 := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(previewText, "togglePreviewingFlips"),
 	)
+	if !game.IsRated() {
+		row4 = append(row4, tgbotapi.NewInlineKeyboardButtonData(consts.HintEmoji+" "+lang.T("Hint"), "hint"))
+	}
 
-	keyboard := append(game.InlineKeyboard(showLegalMoves), buildProfilesRow(game), row2, row3)
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if compact {
+		keyboard = buildLegalMovesKeyboard(game, v.seesSelection(game))
+	} else {
+		keyboard = game.InlineKeyboard(showLegalMoves, v.seesSelection(game), v.theme)
+	}
+	keyboard = append(keyboard, buildProfilesRow(game, v.theme), row2, row4, row3)
 	return &tgbotapi.InlineKeyboardMarkup{
 		InlineKeyboard: keyboard,
 	}
 }
 
-func buildProfilesRow(game *othellogame.Game) []tgbotapi.InlineKeyboardButton {
+func buildLegalMovesKeyboard(game *othellogame.Game, showSelection bool) [][]tgbotapi.InlineKeyboardButton {
+	const maxButtonsInRow = 8
+
+	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0)
+	var row []tgbotapi.InlineKeyboardButton
+	hint, hasHint := game.Hint()
+	selected, hasSelection := game.Selected()
+	for _, move := range game.LegalMoves() {
+		if len(row) == maxButtonsInRow {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+		text := move.String()
+		switch {
+		case showSelection && hasSelection && move == selected:
+			text = consts.SelectedMoveEmoji + text
+		case hasHint && move == hint:
+			text = consts.HintEmoji + text
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			text,
+			fmt.Sprintf("%d_%d", move.X, move.Y),
+		))
+	}
+	if len(row) > 0 {
+		keyboard = append(keyboard, row)
+	}
+	return keyboard
+}
+
+func buildProfilesRow(game *othellogame.Game, t theme.Theme) []tgbotapi.InlineKeyboardButton {
 	whiteProfile := fmt.Sprintf(
 		"%s%s: %d",
-		consts.WhiteDiskEmoji,
+		t.Emoji(cell.White),
 		util.FirstNameElseLastName(game.WhiteUser()),
 		game.WhiteDisks(),
 	)
 	blackProfile := fmt.Sprintf(
 		"%s%s: %d",
-		consts.BlackDiskEmoji,
+		t.Emoji(cell.Black),
 		util.FirstNameElseLastName(game.BlackUser()),
 		game.BlackDisks(),
 	)
@@ -185,65 +507,177 @@ func buildProfilesRow(game *othellogame.Game) []tgbotapi.InlineKeyboardButton {
 func buildGameOverKeyboard(
 	game *othellogame.Game,
 	botUsername string,
-	inline bool,
+	inline, compact bool,
+	v viewer,
 ) *tgbotapi.InlineKeyboardMarkup {
+	lang := v.lang
 	button2data := "replay" + game.ID()
 
 	var button1, button2 tgbotapi.InlineKeyboardButton
 	if inline {
 		inlineQuery := ""
 		button1 = tgbotapi.InlineKeyboardButton{
-			Text:                         "🔄 Play again",
+			Text:                         lang.T("🔄 Play again"),
 			SwitchInlineQueryCurrentChat: &inlineQuery,
 		}
 
 		url := fmt.Sprintf("https://telegram.me/%s?start=%s", botUsername, button2data)
-		button2 = tgbotapi.NewInlineKeyboardButtonURL("🎞 Game replay", url)
+		button2 = tgbotapi.NewInlineKeyboardButtonURL(lang.T("🎞 Game replay"), url)
 	} else {
 		rematchData := fmt.Sprint(
 			"rematch", game.WhiteUser().ID, "&", game.BlackUser().ID, ":", game.ID())
-		button1 = tgbotapi.NewInlineKeyboardButtonData("🔄 Rematch", rematchData)
-		button2 = tgbotapi.NewInlineKeyboardButtonData("🎞 Game replay", button2data)
+		button1 = tgbotapi.NewInlineKeyboardButtonData(lang.T("🔄 Rematch"), rematchData)
+		button2 = tgbotapi.NewInlineKeyboardButtonData(lang.T("🎞 Game replay"), button2data)
 	}
 
 	row := tgbotapi.NewInlineKeyboardRow(button1, button2)
 
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if !compact {
+		keyboard = game.EndInlineKeyboard(v.theme)
+	}
 	return &tgbotapi.InlineKeyboardMarkup{
-		InlineKeyboard: append(game.EndInlineKeyboard(), buildProfilesRow(game), row),
+		InlineKeyboard: append(keyboard, buildProfilesRow(game, v.theme), row),
 	}
 }
 
-func buildMainKeyboard() tgbotapi.ReplyKeyboardMarkup {
+func buildReplayKeyboard(
+	gameID string,
+	current gifmaker.Options,
+	lang i18n.Language,
+) tgbotapi.InlineKeyboardMarkup {
+	speeds := make([]tgbotapi.InlineKeyboardButton, 0, len(gifmaker.Speeds))
+	for _, speed := range gifmaker.Speeds {
+		if speed == current.Speed {
+			continue
+		}
+		speeds = append(speeds, tgbotapi.NewInlineKeyboardButtonData(
+			lang.T(speed.Label()),
+			fmt.Sprintf("replay%s:%s:%s", gameID, speed, current.Format),
+		))
+	}
+
+	formats := make([]tgbotapi.InlineKeyboardButton, 0, len(gifmaker.Formats))
+	for _, format := range gifmaker.Formats {
+		if format == current.Format || !format.Available() {
+			continue
+		}
+		formats = append(formats, tgbotapi.NewInlineKeyboardButtonData(
+			lang.T(format.Label()),
+			fmt.Sprintf("replay%s:%s:%s", gameID, current.Speed, format),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(speeds, formats)
+}
+
+// boardButtons returns the cells of the board of game as buttons with the
+// data dataOf returns for them, marking the legal moves or the last move.
+func boardButtons(
+	game *othellogame.Game,
+	showLegalMoves, markLastMove bool,
+	v viewer,
+	dataOf func(x, y int) string,
+) [][]tgbotapi.InlineKeyboardButton {
+	lastMove, moved := game.LastMove()
+	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0, game.Size())
+	for y, row := range game.Board() {
+		buttons := make([]tgbotapi.InlineKeyboardButton, len(row))
+		for x, c := range row {
+			where := coord.New(x, y)
+			text := v.theme.Emoji(c)
+			switch {
+			case showLegalMoves && game.IsLegalMove(where):
+				text = v.theme.LegalMoveEmoji()
+			case markLastMove && moved && lastMove == where:
+				text = consts.SelectedMoveEmoji
+			}
+			buttons[x] = tgbotapi.NewInlineKeyboardButtonData(text, dataOf(x, y))
+		}
+		keyboard = append(keyboard, buttons)
+	}
+	return keyboard
+}
+
+func buildMainKeyboard(lang i18n.Language) tgbotapi.ReplyKeyboardMarkup {
 	return tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(newGameButtonText),
-			tgbotapi.NewKeyboardButton(scoreboardButtonText),
+			tgbotapi.NewKeyboardButton(lang.T(newGameButtonText)),
+			tgbotapi.NewKeyboardButton(lang.T(scoreboardButtonText)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(lang.T(profileButtonText)),
+			tgbotapi.NewKeyboardButton(lang.T(helpButtonText)),
 		),
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(profileButtonText),
-			tgbotapi.NewKeyboardButton(helpButtonText),
+			tgbotapi.NewKeyboardButton(lang.T(puzzleButtonText)),
+			tgbotapi.NewKeyboardButton(lang.T(settingsButtonText)),
 		),
 	)
 }
 
-func buildGameModeKeyboard() tgbotapi.InlineKeyboardMarkup {
+func buildGameModeKeyboard(lang i18n.Language) tgbotapi.InlineKeyboardMarkup {
+	sizes := make([]tgbotapi.InlineKeyboardButton, 0, len(othellogame.BoardSizes))
+	for _, size := range othellogame.BoardSizes {
+		if size == othellogame.DefaultBoardSize {
+			continue
+		}
+		opts := othellogame.DefaultOptions()
+		opts.BoardSize = size
+		sizes = append(sizes, tgbotapi.NewInlineKeyboardButtonData(
+			"🎲 "+sizeLabel(size),
+			"playWithRandomOpponent"+formatGameOptions(opts),
+		))
+	}
+
+	anti := othellogame.DefaultOptions()
+	anti.Variant = variant.Anti
+
+	randomOpening := othellogame.DefaultOptions()
+	randomOpening.RandomOpening = true
+
+	randomHoles := othellogame.DefaultOptions()
+	randomHoles.Holes = holes.Random
+
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonSwitch("Play with friends!", ""),
+			tgbotapi.NewInlineKeyboardButtonSwitch(lang.T("Play with friends!"), ""),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"Play with random opponents!",
+				lang.T("Play with random opponents!"),
 				"playWithRandomOpponent",
 			),
 		),
+		sizes,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				lang.T("%s with random opponents", lang.T(variant.Anti.Label())),
+				"playWithRandomOpponent"+formatGameOptions(anti),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				lang.T("🔀 Random opening with random opponents"),
+				"playWithRandomOpponent"+formatGameOptions(randomOpening),
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				lang.T("🕳 Random holes with random opponents"),
+				"playWithRandomOpponent"+formatGameOptions(randomHoles),
+			),
+		),
 	)
 }
 
-func buildJoinToGameKeyboard() *tgbotapi.InlineKeyboardMarkup {
+func buildJoinToGameKeyboard(
+	creatorColor cell.Cell,
+	opts othellogame.Options,
+	lang i18n.Language,
+) *tgbotapi.InlineKeyboardMarkup {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Join", "join"),
+			tgbotapi.NewInlineKeyboardButtonData(lang.T("Join"), "join"+formatJoinData(creatorColor, opts)),
 		),
 	)
 	return &keyboard
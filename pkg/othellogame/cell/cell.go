@@ -12,6 +12,8 @@ const (
 	Empty = Cell(0)
 	Black = Cell('b')
 	White = Cell('w')
+	// Hole is a blocked cell that no disk can be placed on.
+	Hole = Cell('x')
 )
 
 func (c Cell) Emoji() string {
@@ -22,6 +24,8 @@ func (c Cell) Emoji() string {
 		return consts.BlackDiskEmoji
 	case White:
 		return consts.WhiteDiskEmoji
+	case Hole:
+		return consts.HoleEmoji
 	default:
 		log.Panicf("Invalid receiver for Cell.Emoji: %v\n", c)
 		panic("")
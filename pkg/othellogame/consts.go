@@ -1,3 +1,15 @@
 package othellogame
 
-const boardSize = 8
+const DefaultBoardSize = 8
+
+// BoardSizes are the sizes of the boards games can be played on.
+var BoardSizes = [...]int{6, 8, 10, 12}
+
+func IsValidBoardSize(size int) bool {
+	for _, s := range BoardSizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
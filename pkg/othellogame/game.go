@@ -1,7 +1,6 @@
 package othellogame
 
 import (
-	"errors"
 	"fmt"
 	"log"
 
@@ -9,7 +8,11 @@ import (
 	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
 	"github.com/ArminGh02/othello-bot/pkg/othellogame/color"
 	"github.com/ArminGh02/othello-bot/pkg/othellogame/direction"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/holes"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/symmetry"
 	"github.com/ArminGh02/othello-bot/pkg/othellogame/turn"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/theme"
 	"github.com/ArminGh02/othello-bot/pkg/util"
 	"github.com/ArminGh02/othello-bot/pkg/util/coord"
 	"github.com/ArminGh02/othello-bot/pkg/util/sets"
@@ -32,37 +35,129 @@ type Game struct {
 	id              string
 	users           [2]*tgbotapi.User
 	disksCount      [2]int
-	board           [boardSize][boardSize]cell.Cell
+	board           [][]cell.Cell
 	turn            turn.Turn
 	placeableCoords sets.Set[coord.Coord]
 	ended           bool
 	whiteStarted    bool
 	movesSequence   []coord.Coord
+	variant         variant.Variant
+	start           *Position
+	handicap        int
+	randomOpening   bool
+	holes           holes.Pattern
+	rated           bool
+	hintsUsed       [2]int
+	hint            *coord.Coord
+	selected        *coord.Coord
+	showFlips       bool
+	hashes          [symmetry.Count]uint64
+	positionHashes  []uint64
+}
+
+type Options struct {
+	BoardSize int
+	Variant   variant.Variant
+
+	// Start is the position to start from instead of the standard one,
+	// and its board size overrides BoardSize.
+	Start *Position
+
+	// RandomOpening starts from a random balanced position
+	// instead of the standard one.
+	RandomOpening bool
+
+	// Handicap is the number of corners given to the black disks,
+	// which the weaker player plays.
+	Handicap int
+
+	// Holes are the cells blocked before the game starts.
+	Holes holes.Pattern
+
+	// Rated games are played by random opponents and have no hints.
+	Rated bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		BoardSize: DefaultBoardSize,
+		Variant:   variant.Classic,
+	}
 }
 
 func New(user1, user2 *tgbotapi.User) *Game {
+	return NewWithOptions(user1, user2, DefaultOptions())
+}
+
+// NewWithOptions returns a game on a board of opts.BoardSize×opts.BoardSize
+// cells, which must be one of BoardSizes.
+func NewWithOptions(user1, user2 *tgbotapi.User, opts Options) *Game {
+	size := opts.BoardSize
+	if opts.Start != nil {
+		size = opts.Start.Size()
+	}
+	if !IsValidBoardSize(size) {
+		log.Panicf("Invalid board size: %d\n", size)
+	}
+
+	var start *Position
+	switch {
+	case opts.Start != nil:
+		start = &Position{Board: opts.Start.copyBoard(), WhiteToMove: opts.Start.WhiteToMove}
+	case opts.RandomOpening:
+		start = RandomOpening(size)
+	default:
+		start = StandardPosition(size, turn.Random() == turn.White)
+	}
+	start.placeHoles(opts.Holes.Coords(size))
+	start.placeHandicap(opts.Handicap, cell.Black)
+
 	game := &Game{
 		id:              xid.New().String(),
 		users:           [2]*tgbotapi.User{user1, user2},
-		disksCount:      [2]int{2, 2},
-		turn:            turn.Random(),
+		board:           start.copyBoard(),
+		turn:            turn.Turn(!start.WhiteToMove),
 		placeableCoords: sets.New[coord.Coord](),
-		movesSequence:   make([]coord.Coord, 0, boardSize*boardSize-4),
+		movesSequence:   make([]coord.Coord, 0, size*size),
+		variant:         opts.Variant,
+		handicap:        opts.Handicap,
+		randomOpening:   opts.RandomOpening,
+		holes:           opts.Holes,
+		rated:           opts.Rated,
 	}
 
-	game.whiteStarted = game.turn == turn.White
-
-	mid := len(game.board)/2 - 1
-	game.board[mid][mid] = cell.White
-	game.board[mid][mid+1] = cell.Black
-	game.board[mid+1][mid] = cell.Black
-	game.board[mid+1][mid+1] = cell.White
-
+	game.hashes = hashesOf(game.board, start.WhiteToMove)
+	game.updateDisksCount()
 	game.updatePlaceableCoords()
+	if game.placeableCoords.IsEmpty() {
+		game.passTurn()
+		game.updatePlaceableCoords()
+		game.ended = game.placeableCoords.IsEmpty()
+	}
+
+	game.whiteStarted = game.turn == turn.White
+	game.start = game.Position()
 
 	return game
 }
 
+// Clone returns a deep copy of game, for searching the moves after
+// the current position without changing it.
+func (game *Game) Clone() *Game {
+	res := *game
+	res.board = make([][]cell.Cell, len(game.board))
+	for i := range game.board {
+		res.board[i] = make([]cell.Cell, len(game.board[i]))
+		copy(res.board[i], game.board[i])
+	}
+	res.placeableCoords = game.placeableCoords.Clone()
+	res.movesSequence = make([]coord.Coord, len(game.movesSequence), cap(game.movesSequence))
+	copy(res.movesSequence, game.movesSequence)
+	res.positionHashes = make([]uint64, len(game.positionHashes), cap(game.positionHashes))
+	copy(res.positionHashes, game.positionHashes)
+	return &res
+}
+
 func (game *Game) String() string {
 	return fmt.Sprintf(
 		"Game between %s and %s",
@@ -77,14 +172,109 @@ func (game *Game) ID() string {
 
 func (game *Game) Board() [][]cell.Cell {
 	res := make([][]cell.Cell, len(game.board))
-	for i := range game.board {
-		res[i] = game.board[i][:]
+	copy(res, game.board)
+	return res
+}
+
+func (game *Game) Size() int {
+	return len(game.board)
+}
+
+func (game *Game) Variant() variant.Variant {
+	return game.variant
+}
+
+func (game *Game) Handicap() int {
+	return game.handicap
+}
+
+// IsRandomOpening reports whether the game started from a random opening.
+func (game *Game) IsRandomOpening() bool {
+	return game.randomOpening
+}
+
+// Holes returns the pattern of the holes the game started with.
+func (game *Game) Holes() holes.Pattern {
+	return game.holes
+}
+
+func (game *Game) IsRated() bool {
+	return game.rated
+}
+
+func (game *Game) WhiteToMove() bool {
+	return game.turn == turn.White
+}
+
+// HintsUsed returns the number of hints user has used in the game.
+func (game *Game) HintsUsed(user *tgbotapi.User) int {
+	if *user == *game.WhiteUser() {
+		return game.hintsUsed[color.White]
+	}
+	return game.hintsUsed[color.Black]
+}
+
+// MarkHint marks where as the move suggested to the active user, until
+// a disk is placed, and counts it as a hint the user has used.
+func (game *Game) MarkHint(where coord.Coord) {
+	game.hint = &where
+	game.hintsUsed[game.turn.Int()]++
+}
+
+// Hint returns the move suggested to the active user, if any.
+func (game *Game) Hint() (coord.Coord, bool) {
+	if game.hint == nil {
+		return coord.Coord{}, false
+	}
+	return *game.hint, true
+}
+
+// Select marks where as the move the active user is about to make until
+// the user confirms it, with the disks it flips if showFlips is true.
+func (game *Game) Select(where coord.Coord, showFlips bool) {
+	game.selected = &where
+	game.showFlips = showFlips
+}
+
+// Selected returns the move being selected, if any.
+func (game *Game) Selected() (coord.Coord, bool) {
+	if game.selected == nil {
+		return coord.Coord{}, false
+	}
+	return *game.selected, true
+}
+
+// SelectedFlips returns the disks the selected move flips, if they are
+// to be shown.
+func (game *Game) SelectedFlips() []coord.Coord {
+	if game.selected == nil || !game.showFlips {
+		return nil
 	}
+	return game.Flips(*game.selected)
+}
+
+// StartPosition returns the position the game started from.
+func (game *Game) StartPosition() *Position {
+	return &Position{
+		Board:       game.start.copyBoard(),
+		WhiteToMove: game.start.WhiteToMove,
+	}
+}
+
+// Position returns the current position of the game.
+func (game *Game) Position() *Position {
+	res := &Position{Board: game.board, WhiteToMove: game.turn == turn.White}
+	res.Board = res.copyBoard()
 	return res
 }
 
 func (game *Game) ActiveColor() string {
-	return game.turn.Cell().Emoji()
+	return game.ActiveCell().Emoji()
+}
+
+// ActiveCell returns the cell of the disks of the player to move.
+func (game *Game) ActiveCell() cell.Cell {
+	return game.turn.Cell()
 }
 
 func (game *Game) ActiveUser() *tgbotapi.User {
@@ -115,7 +305,11 @@ func (game *Game) Winner() *tgbotapi.User {
 	if game.disksCount[color.White] == game.disksCount[color.Black] {
 		return nil
 	}
-	if game.disksCount[color.White] > game.disksCount[color.Black] {
+	whiteWins := game.disksCount[color.White] > game.disksCount[color.Black]
+	if game.variant == variant.Anti {
+		whiteWins = !whiteWins
+	}
+	if whiteWins {
 		return game.users[color.White]
 	}
 	return game.users[color.Black]
@@ -141,24 +335,50 @@ func (game *Game) OpponentOf(user *tgbotapi.User) *tgbotapi.User {
 }
 
 func (game *Game) WinnerColor() string {
+	return game.WinnerCell().Emoji()
+}
+
+// WinnerCell returns the cell of the disks of the winner.
+func (game *Game) WinnerCell() cell.Cell {
 	winner := game.Winner()
 	if winner == nil {
-		log.Panicln("Invalid state: WinnerColor called when the game is a draw.")
+		log.Panicln("Invalid state: WinnerCell called when the game is a draw.")
 	}
 	if *winner == *game.users[color.White] {
-		return cell.White.Emoji()
+		return cell.White
+	}
+	return cell.Black
+}
+
+// InlineKeyboard returns the board as buttons, which mark the hint, and
+// the selected move and the disks it flips if showSelection is true, with
+// the disks of t.
+func (game *Game) InlineKeyboard(showLegalMoves, showSelection bool, t theme.Theme) [][]tgbotapi.InlineKeyboardButton {
+	flips := sets.New[coord.Coord]()
+	selected := game.selected
+	if showSelection {
+		for _, c := range game.SelectedFlips() {
+			flips.Insert(c)
+		}
+	} else {
+		selected = nil
 	}
-	return cell.Black.Emoji()
-}
 
-func (game *Game) InlineKeyboard(showLegalMoves bool) [][]tgbotapi.InlineKeyboardButton {
 	keyboard := make([][]tgbotapi.InlineKeyboardButton, len(game.board))
 	for y := range game.board {
 		keyboard[y] = make([]tgbotapi.InlineKeyboardButton, len(game.board[y]))
 		for x, cell := range game.board[y] {
-			buttonText := cell.Emoji()
-			if showLegalMoves && game.placeableCoords.Contains(coord.New(x, y)) {
-				buttonText = consts.LegalMoveEmoji
+			c := coord.New(x, y)
+			buttonText := t.Emoji(cell)
+			switch {
+			case selected != nil && *selected == c:
+				buttonText = consts.SelectedMoveEmoji
+			case flips.Contains(c):
+				buttonText = consts.FlipEmoji
+			case game.hint != nil && *game.hint == c:
+				buttonText = consts.HintEmoji
+			case showLegalMoves && game.placeableCoords.Contains(c):
+				buttonText = t.LegalMoveEmoji()
 			}
 
 			keyboard[y][x] = tgbotapi.NewInlineKeyboardButtonData(
@@ -170,13 +390,13 @@ func (game *Game) InlineKeyboard(showLegalMoves bool) [][]tgbotapi.InlineKeyboar
 	return keyboard
 }
 
-func (game *Game) EndInlineKeyboard() [][]tgbotapi.InlineKeyboardButton {
+func (game *Game) EndInlineKeyboard(t theme.Theme) [][]tgbotapi.InlineKeyboardButton {
 	keyboard := make([][]tgbotapi.InlineKeyboardButton, len(game.board))
 	for y := range game.board {
 		keyboard[y] = make([]tgbotapi.InlineKeyboardButton, len(game.board[y]))
 		for x, cell := range game.board[y] {
 			keyboard[y][x] = tgbotapi.NewInlineKeyboardButtonData(
-				cell.Emoji(),
+				t.Emoji(cell),
 				"gameOver",
 			)
 		}
@@ -188,42 +408,137 @@ func (game *Game) WhiteStarted() bool {
 	return game.whiteStarted
 }
 
+// Pass is in the moves sequences of games in place of the turns passed
+// by players who had no legal moves.
+var Pass = coord.New(-1, -1)
+
+// MovesSequence returns the cells the disks are placed on in order, with
+// Pass for the turns passed.
 func (game *Game) MovesSequence() []coord.Coord {
 	return game.movesSequence
 }
 
+func (game *Game) LegalMoves() []coord.Coord {
+	res := make([]coord.Coord, 0)
+	for y := range game.board {
+		for x := range game.board[y] {
+			if c := coord.New(x, y); game.placeableCoords.Contains(c) {
+				res = append(res, c)
+			}
+		}
+	}
+	return res
+}
+
+func (game *Game) IsLegalMove(where coord.Coord) bool {
+	return game.placeableCoords.Contains(where)
+}
+
+// Flips returns the disks placing a disk on where would flip.
+func (game *Game) Flips(where coord.Coord) []coord.Coord {
+	res := make([]coord.Coord, 0)
+	for _, dir := range game.findDirectionsToFlip(where, true) {
+		for c := coord.Plus(where, offset[dir]); game.board[c.Y][c.X] != game.turn.Cell(); c.Plus(offset[dir]) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
 func (game *Game) SetTurn(white bool) {
+	if game.WhiteToMove() != white {
+		game.toggleTurnHash()
+	}
 	game.turn = turn.Turn(!white)
+	game.updatePlaceableCoords()
 	if len(game.movesSequence) == 0 {
 		game.whiteStarted = white
+		game.start.WhiteToMove = white
 	}
 }
 
-func (game *Game) PlaceDisk(where coord.Coord, user *tgbotapi.User) error {
+// PlaceDisk places a disk of user on where, and returns whether the
+// opponent of user has no moves afterwards and passes.
+func (game *Game) PlaceDisk(where coord.Coord, user *tgbotapi.User) (passed bool, err error) {
 	if err := game.checkPlacingDisk(where, user); err != nil {
-		return err
+		return false, err
 	}
-	game.PlaceDiskUnchecked(where)
-	return nil
+	return game.PlaceDiskUnchecked(where), nil
 }
 
-func (game *Game) PlaceDiskUnchecked(where coord.Coord) {
-	game.board[where.Y][where.X] = game.turn.Cell()
+// PlaceDiskUnchecked places a disk of the active player on where, which
+// must be a legal move, and returns whether the opponent passes. Passes
+// are recorded in the moves sequence as Pass, but not the two at the end
+// of a game.
+func (game *Game) PlaceDiskUnchecked(where coord.Coord) (passed bool) {
+	game.setCell(where, game.turn.Cell())
 	game.flipDisks(where)
+	game.hint = nil
+	game.selected = nil
 
-	for i := 0; i < 2; i++ {
+	game.passTurn()
+	game.updatePlaceableCoords()
+	if game.placeableCoords.IsEmpty() {
 		game.passTurn()
 		game.updatePlaceableCoords()
-		if !game.placeableCoords.IsEmpty() {
-			break
+		if game.placeableCoords.IsEmpty() {
+			game.ended = true
+		} else {
+			passed = true
 		}
 	}
 
-	if game.placeableCoords.IsEmpty() {
-		game.ended = true
+	game.movesSequence = append(game.movesSequence, where)
+	if passed {
+		game.movesSequence = append(game.movesSequence, Pass)
 	}
+	game.positionHashes = append(game.positionHashes, game.CanonicalHash())
+	return passed
+}
 
-	game.movesSequence = append(game.movesSequence, where)
+// LastTurnPassed returns whether the last player who had the turn, who is
+// the opponent of the active player, passed it.
+func (game *Game) LastTurnPassed() bool {
+	n := len(game.movesSequence)
+	return n > 0 && game.movesSequence[n-1] == Pass
+}
+
+// LastMove returns the cell the last disk is placed on, or false if no
+// disk is placed yet.
+func (game *Game) LastMove() (coord.Coord, bool) {
+	for i := len(game.movesSequence) - 1; i >= 0; i-- {
+		if game.movesSequence[i] != Pass {
+			return game.movesSequence[i], true
+		}
+	}
+	return coord.Coord{}, false
+}
+
+// MovesCount returns the number of disks placed, which is the number of
+// moves without the passes.
+func (game *Game) MovesCount() int {
+	res := 0
+	for _, move := range game.movesSequence {
+		if move != Pass {
+			res++
+		}
+	}
+	return res
+}
+
+// MoveError is why a disk can't be placed on a cell. Its message is in
+// English, which translations of it can be looked up by.
+type MoveError string
+
+const (
+	ErrNotYourTurn = MoveError("It's not your turn!")
+	ErrHole        = MoveError("That cell is a hole!")
+	ErrNotEmpty    = MoveError("That cell is not empty!")
+	ErrIllegalMove = MoveError("You can't place a disk there!")
+)
+
+func (e MoveError) Error() string {
+	return string(e)
 }
 
 func (game *Game) IsTurnOf(user *tgbotapi.User) bool {
@@ -232,13 +547,20 @@ func (game *Game) IsTurnOf(user *tgbotapi.User) bool {
 
 func (game *Game) checkPlacingDisk(where coord.Coord, user *tgbotapi.User) error {
 	if !game.IsTurnOf(user) {
-		return errors.New("It's not your turn!")
+		return ErrNotYourTurn
+	}
+	// the cells of boards of other sizes can be tapped on old messages
+	if !isValidCoord(where, game.Size()) {
+		return ErrIllegalMove
+	}
+	if game.board[where.Y][where.X] == cell.Hole {
+		return ErrHole
 	}
 	if game.board[where.Y][where.X] != cell.Empty {
-		return errors.New("That cell is not empty!")
+		return ErrNotEmpty
 	}
 	if !game.placeableCoords.Contains(where) {
-		return errors.New("You can't place a disk there!")
+		return ErrIllegalMove
 	}
 	return nil
 }
@@ -249,7 +571,7 @@ func (game *Game) flipDisks(where coord.Coord) {
 	for _, dir := range directionsToFlip {
 		c := coord.Plus(where, offset[dir])
 		for game.board[c.Y][c.X] == opponent {
-			game.board[c.Y][c.X] = game.turn.Cell()
+			game.setCell(c, game.turn.Cell())
 			c.Plus(offset[dir])
 		}
 	}
@@ -282,7 +604,7 @@ func (game *Game) findDirectionsToFlip(
 				case game.turn.Cell():
 					res = append(res, dir)
 					break loop
-				case cell.Empty:
+				case cell.Empty, cell.Hole:
 					break loop
 				}
 			}
@@ -297,6 +619,7 @@ func isValidCoord(c coord.Coord, length int) bool {
 
 func (game *Game) passTurn() {
 	game.turn = !game.turn
+	game.toggleTurnHash()
 }
 
 func (game *Game) updatePlaceableCoords() {
@@ -0,0 +1,96 @@
+package holes
+
+import (
+	"math/rand"
+
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+// Pattern is a set of cells blocked before a game starts.
+type Pattern string
+
+const (
+	None     = Pattern("")
+	Corners  = Pattern("corners")
+	XSquares = Pattern("xsquares")
+	Edges    = Pattern("edges")
+	// Random is a pattern drawn for every game, which looks the same
+	// after rotating the board by a quarter turn.
+	Random = Pattern("random")
+)
+
+var Patterns = [...]Pattern{Random, Corners, XSquares, Edges}
+
+func Parse(s string) (Pattern, bool) {
+	for _, p := range Patterns {
+		if string(p) == s {
+			return p, true
+		}
+	}
+	return None, false
+}
+
+func (p Pattern) Label() string {
+	switch p {
+	case Corners:
+		return "holes on the corners"
+	case XSquares:
+		return "holes next to the corners"
+	case Edges:
+		return "holes in the middle of the edges"
+	case Random:
+		return "random holes"
+	default:
+		return "no holes"
+	}
+}
+
+// Coords returns the holes of the pattern on a board of size×size cells.
+func (p Pattern) Coords(size int) []coord.Coord {
+	switch p {
+	case Corners:
+		return rotations(coord.New(0, 0), size)
+	case XSquares:
+		return rotations(coord.New(1, 1), size)
+	case Edges:
+		return append(rotations(coord.New(size/2-1, 0), size), rotations(coord.New(size/2, 0), size)...)
+	case Random:
+		return randomCoords(size)
+	default:
+		return nil
+	}
+}
+
+// randomCoords returns a hole per 4 rows of the board and its rotations,
+// away from the 4×4 cells in the center so the first moves are never blocked.
+func randomCoords(size int) []coord.Coord {
+	res := make([]coord.Coord, 0, size)
+	taken := make(map[coord.Coord]bool)
+	for len(res) < size/4*4 {
+		c := coord.New(rand.Intn(size), rand.Intn(size))
+		if taken[c] || isCentral(c, size) {
+			continue
+		}
+		for _, r := range rotations(c, size) {
+			taken[r] = true
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func isCentral(c coord.Coord, size int) bool {
+	low, high := size/2-2, size/2+1
+	return c.X >= low && c.X <= high && c.Y >= low && c.Y <= high
+}
+
+// rotations returns c rotated by the four quarter turns of the board.
+func rotations(c coord.Coord, size int) []coord.Coord {
+	last := size - 1
+	return []coord.Coord{
+		c,
+		coord.New(last-c.Y, c.X),
+		coord.New(last-c.X, last-c.Y),
+		coord.New(c.Y, last-c.X),
+	}
+}
@@ -0,0 +1,184 @@
+package othellogame
+
+import (
+	"flag"
+	"math/rand"
+	"testing"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+// perft counts the sequences of depth moves from the position of game,
+// where passes are moves too like in the published counts, and ended
+// games have no sequences.
+func perft(game *Game, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	if game.IsEnded() {
+		return 0
+	}
+	res := 0
+	for _, move := range game.LegalMoves() {
+		child := game.Clone()
+		childDepth := depth - 1
+		if child.PlaceDiskUnchecked(move) && childDepth > 0 {
+			childDepth--
+		}
+		res += perft(child, childDepth)
+	}
+	return res
+}
+
+func gameFrom(t testing.TB, position string) *Game {
+	t.Helper()
+	start, err := ParsePosition(position)
+	if err != nil {
+		t.Fatalf("invalid position %q", position)
+	}
+	return NewWithOptions(nil, nil, Options{Start: start})
+}
+
+// The published node counts of the standard 8×8 position. The first
+// passes are at depth 9, which has 32 fewer nodes than if they weren't
+// counted as moves.
+var startPerft = []int{1, 4, 12, 56, 244, 1396, 8200, 55092, 390216, 3005288}
+
+var deepPerft = flag.Bool("deep-perft", false, "check the perft of the start position at depth 9 too, which takes half a minute")
+
+func TestPerftFromStart(t *testing.T) {
+	game := NewWithOptions(nil, nil, Options{Start: StandardPosition(DefaultBoardSize, false)})
+	maxDepth := len(startPerft) - 2
+	switch {
+	case *deepPerft:
+		maxDepth = len(startPerft) - 1
+	case testing.Short():
+		maxDepth = 6
+	}
+	for depth := 1; depth <= maxDepth; depth++ {
+		if got := perft(game, depth); got != startPerft[depth] {
+			t.Errorf("perft(%d) = %d, want %d", depth, got, startPerft[depth])
+		}
+	}
+}
+
+// TestPerftTrickyPositions checks the node counts of positions with holes,
+// passes and other board sizes against referencePerft, which shares no
+// code with the game.
+func TestPerftTrickyPositions(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		depth    int
+	}{
+		{"6×6 start", "------/------/--wb--/--bw--/------/------ b", 5},
+		{"10×10 start", "----------/----------/----------/----------/----wb----/----bw----/----------/----------/----------/---------- w", 4},
+		{"holes", "x------x/-x----x-/--------/---wb---/---bw---/--------/-x----x-/x------x b", 5},
+		{"x-squares and edges", "--------/-bwwww--/-wbbbw--/-wbwbw--/-wbbbw--/-wwwwb--/--------/-------- w", 4},
+		{"pass after the first move", "-wb-----/--------/--------/--------/--------/--------/--------/-----wwb b", 3},
+		{"nobody can move", "bbb-----/--------/--------/--------/--------/--------/--------/-------w b", 2},
+	}
+
+	// late positions of random games, which have passes and endings
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 4; i++ {
+		game := NewWithOptions(nil, nil, Options{Start: StandardPosition(DefaultBoardSize, false)})
+		for plies := 0; plies < 48 && !game.IsEnded(); plies++ {
+			moves := game.LegalMoves()
+			game.PlaceDiskUnchecked(moves[rnd.Intn(len(moves))])
+		}
+		tests = append(tests, struct {
+			name     string
+			position string
+			depth    int
+		}{"random late position", game.Position().String(), 5})
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := gameFrom(t, test.position)
+			start, _ := ParsePosition(test.position)
+			for depth := 1; depth <= test.depth; depth++ {
+				got := perft(game, depth)
+				want := referencePerft(start.Board, toMove(start), depth)
+				if got != want {
+					t.Fatalf("perft(%d) = %d, want %d", depth, got, want)
+				}
+			}
+		})
+	}
+}
+
+func toMove(p *Position) cell.Cell {
+	if p.WhiteToMove {
+		return cell.White
+	}
+	return cell.Black
+}
+
+func referencePerft(board [][]cell.Cell, mover cell.Cell, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	moves := referenceMoves(board, mover)
+	if len(moves) == 0 {
+		if len(referenceMoves(board, mover.Reversed())) == 0 {
+			return 0
+		}
+		return referencePerft(board, mover.Reversed(), depth-1)
+	}
+	res := 0
+	for _, move := range moves {
+		res += referencePerft(referencePlay(board, move, mover), mover.Reversed(), depth-1)
+	}
+	return res
+}
+
+var referenceDirections = [...][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+
+// referenceFlips returns the disks of the opponent of mover that a disk
+// placed on (x, y) would flip.
+func referenceFlips(board [][]cell.Cell, x, y int, mover cell.Cell) []coord.Coord {
+	if board[y][x] != cell.Empty {
+		return nil
+	}
+	size := len(board)
+	var res []coord.Coord
+	for _, d := range referenceDirections {
+		var line []coord.Coord
+		cx, cy := x+d[0], y+d[1]
+		for cx >= 0 && cy >= 0 && cx < size && cy < size && board[cy][cx] == mover.Reversed() {
+			line = append(line, coord.New(cx, cy))
+			cx, cy = cx+d[0], cy+d[1]
+		}
+		if len(line) > 0 && cx >= 0 && cy >= 0 && cx < size && cy < size && board[cy][cx] == mover {
+			res = append(res, line...)
+		}
+	}
+	return res
+}
+
+func referenceMoves(board [][]cell.Cell, mover cell.Cell) []coord.Coord {
+	var res []coord.Coord
+	for y := range board {
+		for x := range board[y] {
+			if len(referenceFlips(board, x, y, mover)) > 0 {
+				res = append(res, coord.New(x, y))
+			}
+		}
+	}
+	return res
+}
+
+func referencePlay(board [][]cell.Cell, move coord.Coord, mover cell.Cell) [][]cell.Cell {
+	res := make([][]cell.Cell, len(board))
+	for y := range board {
+		res[y] = append([]cell.Cell(nil), board[y]...)
+	}
+	for _, c := range referenceFlips(board, move.X, move.Y, mover) {
+		res[c.Y][c.X] = mover
+	}
+	res[move.Y][move.X] = mover
+	return res
+}
@@ -0,0 +1,225 @@
+package othellogame
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var errInvalidPosition = errors.New("invalid position")
+
+// Position is a board together with the color to move on it.
+type Position struct {
+	Board       [][]cell.Cell
+	WhiteToMove bool
+}
+
+// ParsePosition parses positions written like
+// "--------/--------/--------/---wb---/---bw---/--------/--------/-------- b",
+// with the rows from top to bottom separated by slashes, "-" for empty cells,
+// "x" for holes, "w" and "b" for disks, and the color to move at the end.
+func ParsePosition(s string) (*Position, error) {
+	rows, toMove, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return nil, errInvalidPosition
+	}
+
+	res := &Position{}
+	switch strings.TrimSpace(toMove) {
+	case "w":
+		res.WhiteToMove = true
+	case "b":
+		res.WhiteToMove = false
+	default:
+		return nil, errInvalidPosition
+	}
+
+	for _, row := range strings.Split(rows, "/") {
+		cells := make([]cell.Cell, 0, len(row))
+		for _, r := range row {
+			switch r {
+			case '-':
+				cells = append(cells, cell.Empty)
+			case 'w':
+				cells = append(cells, cell.White)
+			case 'b':
+				cells = append(cells, cell.Black)
+			case 'x':
+				cells = append(cells, cell.Hole)
+			default:
+				return nil, errInvalidPosition
+			}
+		}
+		res.Board = append(res.Board, cells)
+	}
+
+	if !IsValidBoardSize(len(res.Board)) {
+		return nil, errInvalidPosition
+	}
+	for _, row := range res.Board {
+		if len(row) != len(res.Board) {
+			return nil, errInvalidPosition
+		}
+	}
+	return res, nil
+}
+
+func (p *Position) String() string {
+	var b strings.Builder
+	for y, row := range p.Board {
+		if y > 0 {
+			b.WriteByte('/')
+		}
+		for _, c := range row {
+			switch c {
+			case cell.White:
+				b.WriteByte('w')
+			case cell.Black:
+				b.WriteByte('b')
+			case cell.Hole:
+				b.WriteByte('x')
+			default:
+				b.WriteByte('-')
+			}
+		}
+	}
+	if p.WhiteToMove {
+		b.WriteString(" w")
+	} else {
+		b.WriteString(" b")
+	}
+	return b.String()
+}
+
+func (p *Position) Size() int {
+	return len(p.Board)
+}
+
+func (p *Position) copyBoard() [][]cell.Cell {
+	res := make([][]cell.Cell, len(p.Board))
+	for i := range p.Board {
+		res[i] = make([]cell.Cell, len(p.Board[i]))
+		copy(res[i], p.Board[i])
+	}
+	return res
+}
+
+// StandardPosition returns the four-disk cross in the center of the board.
+func StandardPosition(size int, whiteToMove bool) *Position {
+	res := &Position{
+		Board:       make([][]cell.Cell, size),
+		WhiteToMove: whiteToMove,
+	}
+	for i := range res.Board {
+		res.Board[i] = make([]cell.Cell, size)
+	}
+
+	mid := size/2 - 1
+	res.Board[mid][mid] = cell.White
+	res.Board[mid][mid+1] = cell.Black
+	res.Board[mid+1][mid] = cell.Black
+	res.Board[mid+1][mid+1] = cell.White
+	return res
+}
+
+// IsStandard reports whether p is the four-disk cross the games start from
+// unless other options are given.
+func (p *Position) IsStandard() bool {
+	return p.String() == StandardPosition(p.Size(), p.WhiteToMove).String()
+}
+
+// placeHandicap places disks of color on up to four corners of the board
+// that are not holes.
+func (p *Position) placeHandicap(corners int, color cell.Cell) {
+	last := len(p.Board) - 1
+	order := [...]coord.Coord{{0, 0}, {last, last}, {last, 0}, {0, last}}
+	for i := 0; i < corners && i < len(order); i++ {
+		if p.Board[order[i].Y][order[i].X] == cell.Empty {
+			p.Board[order[i].Y][order[i].X] = color
+		}
+	}
+}
+
+// placeHoles blocks the empty cells of holes.
+func (p *Position) placeHoles(holes []coord.Coord) {
+	for _, c := range holes {
+		if p.Board[c.Y][c.X] == cell.Empty {
+			p.Board[c.Y][c.X] = cell.Hole
+		}
+	}
+}
+
+// HasHoles reports whether any cell of p is a hole.
+func (p *Position) HasHoles() bool {
+	for _, row := range p.Board {
+		for _, c := range row {
+			if c == cell.Hole {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+const (
+	maxOpeningTries          = 1000
+	maxOpeningDiskDifference = 2
+	maxOpeningMobilityGap    = 2
+)
+
+// RandomOpening returns a position reached by random moves from the standard
+// start, like the openings of XOT ("eXtended Othello Thor"). Openings
+// are drawn until one looks balanced: nobody has a corner, and both colors
+// have about as many disks and legal moves. There are as many moves as
+// the board has rows, 8 on the standard board like in XOT.
+func RandomOpening(size int) *Position {
+	var res *Position
+	for try := 0; try < maxOpeningTries; try++ {
+		opts := DefaultOptions()
+		opts.BoardSize = size
+		opts.Start = StandardPosition(size, rand.Intn(2) == 0)
+		game := NewWithOptions(&tgbotapi.User{}, &tgbotapi.User{}, opts)
+		for i := 0; i < size && !game.IsEnded(); i++ {
+			moves := game.LegalMoves()
+			game.PlaceDiskUnchecked(moves[rand.Intn(len(moves))])
+		}
+
+		res = game.Position()
+		if !game.IsEnded() && isBalanced(game) {
+			break
+		}
+	}
+	return res
+}
+
+func isBalanced(game *Game) bool {
+	last := game.Size() - 1
+	for _, c := range [...]coord.Coord{{0, 0}, {last, 0}, {0, last}, {last, last}} {
+		if game.board[c.Y][c.X] != cell.Empty {
+			return false
+		}
+	}
+
+	if abs(game.WhiteDisks()-game.BlackDisks()) > maxOpeningDiskDifference {
+		return false
+	}
+
+	mobility := len(game.LegalMoves())
+	game.passTurn()
+	game.updatePlaceableCoords()
+	opponentMobility := len(game.LegalMoves())
+	game.passTurn()
+	game.updatePlaceableCoords()
+	return abs(mobility-opponentMobility) <= maxOpeningMobilityGap
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
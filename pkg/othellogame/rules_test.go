@@ -0,0 +1,222 @@
+package othellogame
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/direction"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestPassesAndEndings(t *testing.T) {
+	tests := []struct {
+		name            string
+		position        string
+		moves           []coord.Coord
+		wantWhiteToMove bool
+		wantEnded       bool
+		wantWhite       int
+		wantBlack       int
+		wantPasses      int
+	}{
+		{
+			name:     "white passes",
+			position: "-wb-----/--------/--------/--------/--------/--------/--------/-----wwb b",
+			moves:    []coord.Coord{coord.New(0, 0)},
+			// black still has e8
+			wantWhiteToMove: false,
+			wantWhite:       2,
+			wantBlack:       4,
+			wantPasses:      1,
+		},
+		{
+			name:       "wipe-out after a pass",
+			position:   "-wb-----/--------/--------/--------/--------/--------/--------/-----wwb b",
+			moves:      []coord.Coord{coord.New(0, 0), coord.New(4, 7)},
+			wantEnded:  true,
+			wantBlack:  7,
+			wantPasses: 1,
+		},
+		{
+			name:      "double pass with empty cells",
+			position:  "-wb-----/--------/--------/--------/--------/--------/--------/-------w b",
+			moves:     []coord.Coord{coord.New(0, 0)},
+			wantEnded: true,
+			wantWhite: 1,
+			wantBlack: 3,
+		},
+		{
+			name:      "full board",
+			position:  "-wbbbbbb/wwwwwwww/wwwwwwww/wwwwwwww/wwwwwwww/wwwwwwww/wwwwwwww/wwwwwwww b",
+			moves:     []coord.Coord{coord.New(0, 0)},
+			wantEnded: true,
+			wantWhite: 56,
+			wantBlack: 8,
+		},
+		{
+			name:      "nobody can move from the start",
+			position:  "bbb-----/--------/--------/--------/--------/--------/--------/-------w b",
+			wantEnded: true,
+			wantWhite: 1,
+			wantBlack: 3,
+		},
+		{
+			name:            "black passes from the start",
+			position:        "bbb-----/--------/--------/--------/--------/--------/--------/-----bbw b",
+			wantWhiteToMove: true,
+			wantWhite:       1,
+			wantBlack:       5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := gameFrom(t, test.position)
+			passes := 0
+			for _, move := range test.moves {
+				if !game.IsLegalMove(move) {
+					t.Fatalf("%v is illegal", move)
+				}
+				if game.PlaceDiskUnchecked(move) {
+					passes++
+					if !game.LastTurnPassed() {
+						t.Errorf("the pass after %v isn't the last turn", move)
+					}
+				}
+			}
+			if game.IsEnded() != test.wantEnded {
+				t.Errorf("ended: %v, want %v", game.IsEnded(), test.wantEnded)
+			}
+			if !test.wantEnded && game.WhiteToMove() != test.wantWhiteToMove {
+				t.Errorf("white to move: %v, want %v", game.WhiteToMove(), test.wantWhiteToMove)
+			}
+			if game.WhiteDisks() != test.wantWhite || game.BlackDisks() != test.wantBlack {
+				t.Errorf("disks: %d white and %d black, want %d and %d",
+					game.WhiteDisks(), game.BlackDisks(), test.wantWhite, test.wantBlack)
+			}
+			if passes != test.wantPasses {
+				t.Errorf("%d passes, want %d", passes, test.wantPasses)
+			}
+			if got, want := len(game.MovesSequence()), len(test.moves)+test.wantPasses; got != want {
+				t.Errorf("%d moves recorded, want %d", got, want)
+			}
+			if got := game.MovesCount(); got != len(test.moves) {
+				t.Errorf("%d moves counted, want %d", got, len(test.moves))
+			}
+		})
+	}
+}
+
+// TestFlipsInEveryDirection places a disk at one end of the longest line of
+// each direction of offset, which has the opponent's disks up to a disk of
+// the mover at the other end, on the edges and the diagonals of the board.
+func TestFlipsInEveryDirection(t *testing.T) {
+	const size = DefaultBoardSize
+	for dir := direction.Direction(0); dir < direction.Count; dir++ {
+		d := offset[dir]
+		var from coord.Coord
+		if d.X < 0 {
+			from.X = size - 1
+		}
+		if d.Y < 0 {
+			from.Y = size - 1
+		}
+
+		p := StandardPosition(size, false)
+		for y := range p.Board {
+			for x := range p.Board[y] {
+				p.Board[y][x] = cell.Empty
+			}
+		}
+		c := coord.Plus(from, d)
+		var flipped []coord.Coord
+		for i := 1; i < size-1; i++ {
+			p.Board[c.Y][c.X] = cell.White
+			flipped = append(flipped, c)
+			c.Plus(d)
+		}
+		p.Board[c.Y][c.X] = cell.Black
+
+		game := NewWithOptions(nil, nil, Options{Start: p})
+		if got := game.Flips(from); len(got) != len(flipped) {
+			t.Errorf("direction %d: Flips returned %d disks, want %d", dir, len(got), len(flipped))
+		}
+		game.PlaceDiskUnchecked(from)
+		for _, f := range flipped {
+			if game.Board()[f.Y][f.X] != cell.Black {
+				t.Errorf("direction %d: %v isn't flipped", dir, f)
+			}
+		}
+		if game.BlackDisks() != size || game.WhiteDisks() != 0 || !game.IsEnded() {
+			t.Errorf("direction %d: %d black and %d white disks", dir, game.BlackDisks(), game.WhiteDisks())
+		}
+	}
+}
+
+func TestNoFlipsWithoutClosingDisk(t *testing.T) {
+	// the line of white disks runs into a hole and the edge
+	game := gameFrom(t, "-wwx----/w-------/w-------/b-------/--------/--------/--------/-------- b")
+	game.PlaceDiskUnchecked(coord.New(0, 0))
+	board := game.Board()
+	if board[0][1] != cell.White || board[0][2] != cell.White {
+		t.Error("disks flipped without a black disk closing the line")
+	}
+	if board[1][0] != cell.Black || board[2][0] != cell.Black {
+		t.Error("the closed line isn't flipped")
+	}
+}
+
+// TestDiskCountInvariants plays random games and checks after each move
+// that the counts of updateDisksCount match the board, that a move adds
+// one disk and moves the flipped ones to the mover.
+func TestDiskCountInvariants(t *testing.T) {
+	property := func(seed int64, sizeIndex uint8) bool {
+		rnd := rand.New(rand.NewSource(seed))
+		size := BoardSizes[int(sizeIndex)%len(BoardSizes)]
+		game := NewWithOptions(nil, nil, Options{BoardSize: size})
+		for !game.IsEnded() {
+			moves := game.LegalMoves()
+			move := moves[rnd.Intn(len(moves))]
+			flips := len(game.Flips(move))
+			whiteMoves := game.WhiteToMove()
+			white, black := game.WhiteDisks(), game.BlackDisks()
+
+			game.PlaceDiskUnchecked(move)
+
+			wantWhite, wantBlack := white-flips, black+1+flips
+			if whiteMoves {
+				wantWhite, wantBlack = white+1+flips, black-flips
+			}
+			if game.WhiteDisks() != wantWhite || game.BlackDisks() != wantBlack {
+				return false
+			}
+			counted := map[cell.Cell]int{}
+			for _, row := range game.Board() {
+				for _, c := range row {
+					counted[c]++
+				}
+			}
+			if counted[cell.White] != game.WhiteDisks() || counted[cell.Black] != game.BlackDisks() {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 50}); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestPlaceDiskOutsideBoard taps the cells of an 8×8 board on a 6×6 one,
+// which old messages of other games still have.
+func TestPlaceDiskOutsideBoard(t *testing.T) {
+	white, black := &tgbotapi.User{ID: 1}, &tgbotapi.User{ID: 2}
+	game := NewWithOptions(white, black, Options{Start: StandardPosition(6, false)})
+	for _, where := range []coord.Coord{coord.New(6, 0), coord.New(0, 7), coord.New(-1, 2)} {
+		if _, err := game.PlaceDisk(where, black); err != ErrIllegalMove {
+			t.Errorf("placing a disk on %v: got %v, want %v", where, err, ErrIllegalMove)
+		}
+	}
+}
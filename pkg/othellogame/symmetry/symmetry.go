@@ -0,0 +1,60 @@
+// Package symmetry has the rotations and reflections of square boards,
+// which turn positions into ones that play the same.
+package symmetry
+
+import "github.com/ArminGh02/othello-bot/pkg/util/coord"
+
+const Count = 8
+
+type Symmetry int
+
+const (
+	Identity Symmetry = iota
+	// MirrorColumns swaps the columns on the left and the right.
+	MirrorColumns
+	// MirrorRows swaps the rows on the top and the bottom.
+	MirrorRows
+	Rotate180
+	// Transpose mirrors the board along the diagonal through a1.
+	Transpose
+	// Rotate90 rotates the board clockwise.
+	Rotate90
+	Rotate270
+	// AntiTranspose mirrors the board along the diagonal through h1.
+	AntiTranspose
+)
+
+// Apply returns the cell c is moved to by s on a board of size×size cells.
+func (s Symmetry) Apply(c coord.Coord, size int) coord.Coord {
+	last := size - 1
+	switch s {
+	case MirrorColumns:
+		return coord.New(last-c.X, c.Y)
+	case MirrorRows:
+		return coord.New(c.X, last-c.Y)
+	case Rotate180:
+		return coord.New(last-c.X, last-c.Y)
+	case Transpose:
+		return coord.New(c.Y, c.X)
+	case Rotate90:
+		return coord.New(last-c.Y, c.X)
+	case Rotate270:
+		return coord.New(c.Y, last-c.X)
+	case AntiTranspose:
+		return coord.New(last-c.Y, last-c.X)
+	default:
+		return c
+	}
+}
+
+// Inverse returns the symmetry moving the cells back to where s took them from.
+func (s Symmetry) Inverse() Symmetry {
+	switch s {
+	case Rotate90:
+		return Rotate270
+	case Rotate270:
+		return Rotate90
+	default:
+		return s
+	}
+}
@@ -0,0 +1,27 @@
+package variant
+
+type Variant string
+
+const (
+	Classic = Variant("classic")
+	// Anti is Anti-Othello, in which the player with fewer disks wins.
+	Anti = Variant("anti")
+)
+
+var Variants = [...]Variant{Classic, Anti}
+
+func Parse(s string) (Variant, bool) {
+	for _, v := range Variants {
+		if string(v) == s {
+			return v, true
+		}
+	}
+	return Classic, false
+}
+
+func (v Variant) Label() string {
+	if v == Anti {
+		return "🙃 Anti-Othello"
+	}
+	return "Othello"
+}
@@ -0,0 +1,139 @@
+package othellogame
+
+import (
+	"math/rand"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/symmetry"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+// Positions are hashed with Zobrist hashing: the hash of a position is the
+// XOR of random keys of its disks and holes, the size of its board and the
+// color to move, so placing and flipping a disk updates it with a few XORs.
+// Games keep the hashes of their boards turned by each of the symmetries,
+// and the smallest of them is the same for all positions that play the same.
+
+// zobristSize is the size of the largest of BoardSizes.
+const zobristSize = 12
+
+type zobristKeys struct {
+	cells       [zobristSize * zobristSize][3]uint64
+	sizes       [zobristSize + 1]uint64
+	whiteToMove uint64
+}
+
+// zobrist are drawn from a fixed seed, as the hashes of games are saved.
+var zobrist = func() *zobristKeys {
+	rnd := rand.New(rand.NewSource(20220718))
+	res := &zobristKeys{whiteToMove: rnd.Uint64()}
+	for i := range res.cells {
+		for j := range res.cells[i] {
+			res.cells[i][j] = rnd.Uint64()
+		}
+	}
+	for i := range res.sizes {
+		res.sizes[i] = rnd.Uint64()
+	}
+	return res
+}()
+
+func cellKey(c coord.Coord, content cell.Cell) uint64 {
+	keys := &zobrist.cells[c.Y*zobristSize+c.X]
+	switch content {
+	case cell.Black:
+		return keys[0]
+	case cell.White:
+		return keys[1]
+	case cell.Hole:
+		return keys[2]
+	default:
+		return 0
+	}
+}
+
+// hashesOf returns the hashes of board with the color to move,
+// turned by each of the symmetries.
+func hashesOf(board [][]cell.Cell, whiteToMove bool) [symmetry.Count]uint64 {
+	size := len(board)
+	var res [symmetry.Count]uint64
+	for s := range res {
+		res[s] = zobrist.sizes[size]
+		if whiteToMove {
+			res[s] ^= zobrist.whiteToMove
+		}
+	}
+	for y, row := range board {
+		for x, c := range row {
+			if c == cell.Empty {
+				continue
+			}
+			for s := range res {
+				res[s] ^= cellKey(symmetry.Symmetry(s).Apply(coord.New(x, y), size), c)
+			}
+		}
+	}
+	return res
+}
+
+func minHash(hashes [symmetry.Count]uint64) uint64 {
+	res := hashes[0]
+	for _, h := range hashes[1:] {
+		if h < res {
+			res = h
+		}
+	}
+	return res
+}
+
+// Hash returns the Zobrist hash of the current position of the game.
+func (game *Game) Hash() uint64 {
+	return game.hashes[symmetry.Identity]
+}
+
+// CanonicalHash returns the same hash for the positions that are the same
+// up to the symmetries of the board, so it identifies transposed games too.
+func (game *Game) CanonicalHash() uint64 {
+	return minHash(game.hashes)
+}
+
+// PositionHashes returns the canonical hashes of the positions after each
+// of the moves of the game.
+func (game *Game) PositionHashes() []uint64 {
+	return game.positionHashes
+}
+
+// Fingerprint returns the same hash for games that went through the same
+// positions in the same order, up to the symmetries of the board.
+func (game *Game) Fingerprint() uint64 {
+	// FNV-1a over the hashes rather than bytes
+	const prime = 1099511628211
+	res := game.start.CanonicalHash()
+	for _, hash := range game.positionHashes {
+		res = (res ^ hash) * prime
+	}
+	return res
+}
+
+// CanonicalHash is like Game.CanonicalHash, for the positions games aren't
+// playing.
+func (p *Position) CanonicalHash() uint64 {
+	return minHash(hashesOf(p.Board, p.WhiteToMove))
+}
+
+// setCell changes the content of the cell at where and updates the hashes.
+func (game *Game) setCell(where coord.Coord, content cell.Cell) {
+	size := len(game.board)
+	old := game.board[where.Y][where.X]
+	for s := range game.hashes {
+		to := symmetry.Symmetry(s).Apply(where, size)
+		game.hashes[s] ^= cellKey(to, old) ^ cellKey(to, content)
+	}
+	game.board[where.Y][where.X] = content
+}
+
+func (game *Game) toggleTurnHash() {
+	for s := range game.hashes {
+		game.hashes[s] ^= zobrist.whiteToMove
+	}
+}
@@ -0,0 +1,66 @@
+package othellogame
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/symmetry"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+// TestIncrementalHashes plays random games and checks after each move that
+// the hashes kept up to date match the ones of the board.
+func TestIncrementalHashes(t *testing.T) {
+	property := func(seed int64, sizeIndex uint8) bool {
+		rnd := rand.New(rand.NewSource(seed))
+		size := BoardSizes[int(sizeIndex)%len(BoardSizes)]
+		game := NewWithOptions(nil, nil, Options{BoardSize: size})
+		for !game.IsEnded() {
+			moves := game.LegalMoves()
+			game.PlaceDiskUnchecked(moves[rnd.Intn(len(moves))])
+			if game.hashes != hashesOf(game.Board(), game.WhiteToMove()) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 50}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCanonicalHashOfSymmetries(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	game := NewWithOptions(nil, nil, Options{Start: StandardPosition(DefaultBoardSize, false)})
+	for i := 0; i < 20; i++ {
+		moves := game.LegalMoves()
+		game.PlaceDiskUnchecked(moves[rnd.Intn(len(moves))])
+	}
+
+	p := game.Position()
+	for s := symmetry.Symmetry(0); s < symmetry.Count; s++ {
+		turned := &Position{Board: make([][]cell.Cell, p.Size()), WhiteToMove: p.WhiteToMove}
+		for y := range turned.Board {
+			turned.Board[y] = make([]cell.Cell, p.Size())
+		}
+		for y, row := range p.Board {
+			for x, c := range row {
+				to := s.Apply(coord.New(x, y), p.Size())
+				turned.Board[to.Y][to.X] = c
+			}
+		}
+		if turned.CanonicalHash() != game.CanonicalHash() {
+			t.Errorf("symmetry %d changes the canonical hash", s)
+		}
+		if back := s.Inverse().Apply(s.Apply(coord.New(1, 2), 8), 8); back != coord.New(1, 2) {
+			t.Errorf("the inverse of symmetry %d moves b3 to %v", s, back)
+		}
+	}
+
+	p.WhiteToMove = !p.WhiteToMove
+	if p.CanonicalHash() == game.CanonicalHash() {
+		t.Error("the color to move doesn't change the hash")
+	}
+}
@@ -0,0 +1,153 @@
+// Package puzzle finds othello positions with a move better than all the
+// others, which players are asked to find. Puzzles are a single move;
+// forced sequences of several moves aren't asked.
+package puzzle
+
+import (
+	"math/rand"
+
+	"github.com/ArminGh02/othello-bot/pkg/engine"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/variant"
+	"github.com/ArminGh02/othello-bot/pkg/util/coord"
+)
+
+const (
+	// MaxEmptyCells is the most empty cells the positions of puzzles have,
+	// since they are solved to the end of the game.
+	MaxEmptyCells = 10
+	// MinEmptyCells is the fewest, so that there is something to find.
+	MinEmptyCells = 6
+	// MinMargin is how many disks the solution must win by more than the
+	// other moves, unless it's the only move that wins.
+	MinMargin = 8
+
+	// shallowDepth is how many moves ahead the search that rates the
+	// difficulty looks, which is about as far as casual players do.
+	shallowDepth = 2
+)
+
+type Puzzle struct {
+	Position *othellogame.Position
+	// Solution is the best move of the active player.
+	Solution coord.Coord
+	// Margin is the number of disks Solution wins by with perfect play.
+	Margin int
+	// NextBest is the number of disks the second best move wins by,
+	// which is zero or negative if it doesn't win.
+	NextBest int
+}
+
+// Find returns the puzzle of the position of game, if it's an othello
+// game on the standard board with a few empty cells where exactly one move
+// wins, or the best move wins by MinMargin disks more than the others.
+func Find(game *othellogame.Game) (*Puzzle, bool) {
+	if game.IsEnded() || game.Variant() == variant.Anti || game.Size() != othellogame.DefaultBoardSize {
+		return nil, false
+	}
+	if empty := emptyCells(game); empty < MinEmptyCells || empty > MaxEmptyCells {
+		return nil, false
+	}
+	moves := game.LegalMoves()
+	if len(moves) < 2 {
+		return nil, false
+	}
+
+	scores := engine.SolveMoves(game)
+	best, next := moves[0], moves[1]
+	if scores[next] > scores[best] {
+		best, next = next, best
+	}
+	for _, move := range moves[2:] {
+		switch score := scores[move]; {
+		case score > scores[best]:
+			best, next = move, best
+		case score > scores[next]:
+			next = move
+		}
+	}
+	p := &Puzzle{
+		Position: game.Position(),
+		Solution: best,
+		Margin:   scores[best],
+		NextBest: scores[next],
+	}
+	if p.Margin <= 0 || p.NextBest >= p.Margin {
+		return nil, false
+	}
+	if p.NextBest > 0 && p.Margin-p.NextBest < MinMargin {
+		return nil, false
+	}
+	return p, true
+}
+
+// Generate plays random games to a random number of empty cells until one
+// reaches the position of a puzzle.
+func Generate(rnd *rand.Rand) *Puzzle {
+	for {
+		game := othellogame.NewWithOptions(nil, nil, othellogame.Options{
+			Start: othellogame.StandardPosition(othellogame.DefaultBoardSize, false),
+		})
+		empty := MinEmptyCells + rnd.Intn(MaxEmptyCells-MinEmptyCells+1)
+		for !game.IsEnded() && emptyCells(game) > empty {
+			moves := game.LegalMoves()
+			game.PlaceDiskUnchecked(moves[rnd.Intn(len(moves))])
+		}
+		if p, ok := Find(game); ok {
+			return p
+		}
+	}
+}
+
+// Difficulty rates how hard the solution is to see from 1 to 5, adding one
+// for each of: more than four legal moves, a solution off the corners,
+// another move that flips more disks, and a shallow search of the engine
+// missing it.
+func (p *Puzzle) Difficulty() int {
+	game := p.Game()
+	moves := game.LegalMoves()
+	res := 1
+	if len(moves) > 4 {
+		res++
+	}
+	if last := game.Size() - 1; (p.Solution.X != 0 && p.Solution.X != last) ||
+		(p.Solution.Y != 0 && p.Solution.Y != last) {
+		res++
+	}
+	flips := len(game.Flips(p.Solution))
+	for _, move := range moves {
+		if len(game.Flips(move)) > flips {
+			res++
+			break
+		}
+	}
+	if move, _ := engine.BestMove(game, shallowDepth); move != p.Solution {
+		res++
+	}
+	return res
+}
+
+// Hash returns the canonical hash of the position of the puzzle, which is
+// the same for the puzzles that are the same up to the symmetries of the
+// board.
+func (p *Puzzle) Hash() uint64 {
+	return p.Position.CanonicalHash()
+}
+
+// Game returns a game in the position of the puzzle.
+func (p *Puzzle) Game() *othellogame.Game {
+	return othellogame.NewWithOptions(nil, nil, othellogame.Options{Start: p.Position})
+}
+
+func emptyCells(game *othellogame.Game) int {
+	res := 0
+	for _, row := range game.Board() {
+		for _, c := range row {
+			if c == cell.Empty {
+				res++
+			}
+		}
+	}
+	return res
+}
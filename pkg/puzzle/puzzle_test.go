@@ -0,0 +1,31 @@
+package puzzle
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ArminGh02/othello-bot/pkg/engine"
+)
+
+func TestGenerate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 3; i++ {
+		p := Generate(rnd)
+		game := p.Game()
+		if !game.IsLegalMove(p.Solution) {
+			t.Fatalf("the solution %v of %s is illegal", p.Solution, p.Position)
+		}
+		for move, score := range engine.SolveMoves(game) {
+			if move != p.Solution && score >= p.Margin {
+				t.Errorf("%v wins %s by %d disks, as much as the solution %v", move, p.Position, score, p.Solution)
+			}
+		}
+		if d := p.Difficulty(); d < 1 || d > 5 {
+			t.Errorf("the difficulty of %s is %d", p.Position, d)
+		}
+		again, ok := Find(game)
+		if !ok || again.Solution != p.Solution || again.Margin != p.Margin || again.NextBest != p.NextBest {
+			t.Errorf("the puzzle of %s isn't found again", p.Position)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package theme has the sets of emojis players can choose to see
+// the disks and the legal moves of boards with.
+package theme
+
+import (
+	"github.com/ArminGh02/othello-bot/pkg/consts"
+	"github.com/ArminGh02/othello-bot/pkg/othellogame/cell"
+)
+
+type Theme string
+
+const (
+	Classic      = Theme("classic")
+	HighContrast = Theme("contrast")
+	// Shapes tells the disks apart by their shapes,
+	// for players who can't tell their colors apart.
+	Shapes    = Theme("shapes")
+	Nowruz    = Theme("nowruz")
+	Halloween = Theme("halloween")
+)
+
+var Themes = [...]Theme{Classic, HighContrast, Shapes, Nowruz, Halloween}
+
+type emojis struct {
+	black, white, legalMove, hole string
+}
+
+var themeEmojis = map[Theme]emojis{
+	Classic:      {consts.BlackDiskEmoji, consts.WhiteDiskEmoji, consts.LegalMoveEmoji, consts.HoleEmoji},
+	HighContrast: {"🔵", "🟡", "➕", consts.HoleEmoji},
+	Shapes:       {"✖️", "⭕️", "▫️", consts.HoleEmoji},
+	Nowruz:       {"🐟", "🥚", "🌱", consts.HoleEmoji},
+	Halloween:    {"🦇", "🎃", "🕸", "⚰️"},
+}
+
+// Parse returns the theme named s, or Classic if there is none.
+func Parse(s string) (Theme, bool) {
+	for _, t := range Themes {
+		if string(t) == s {
+			return t, true
+		}
+	}
+	return Classic, false
+}
+
+func (t Theme) Label() string {
+	switch t {
+	case HighContrast:
+		return "🔵 High contrast"
+	case Shapes:
+		return "⭕️ Shapes"
+	case Nowruz:
+		return "🐟 Nowruz"
+	case Halloween:
+		return "🎃 Halloween"
+	default:
+		return "⚫️ Classic"
+	}
+}
+
+// Emoji returns the emoji of c in the theme, like cell.Cell.Emoji does
+// in the classic one.
+func (t Theme) Emoji(c cell.Cell) string {
+	e := t.emojis()
+	switch c {
+	case cell.Black:
+		return e.black
+	case cell.White:
+		return e.white
+	case cell.Hole:
+		return e.hole
+	default:
+		return c.Emoji()
+	}
+}
+
+func (t Theme) LegalMoveEmoji() string {
+	return t.emojis().legalMove
+}
+
+func (t Theme) emojis() emojis {
+	if e, ok := themeEmojis[t]; ok {
+		return e
+	}
+	return themeEmojis[Classic]
+}
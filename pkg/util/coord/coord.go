@@ -1,5 +1,10 @@
 package coord
 
+import (
+	"fmt"
+	"strconv"
+)
+
 type Coord struct {
 	X int
 	Y int
@@ -21,3 +26,21 @@ func (c *Coord) Plus(other Coord) {
 	c.X += other.X
 	c.Y += other.Y
 }
+
+// String returns the coordinate in algebraic notation, like "d3".
+func (c Coord) String() string {
+	return fmt.Sprintf("%c%d", 'a'+c.X, c.Y+1)
+}
+
+// Parse parses a coordinate in algebraic notation, like "d3", on a board
+// of size×size cells.
+func Parse(s string, size int) (Coord, error) {
+	if len(s) < 2 || s[0] < 'a' || int(s[0]-'a') >= size || s[1] < '1' || s[1] > '9' {
+		return Coord{}, fmt.Errorf("invalid move %q", s)
+	}
+	rank, err := strconv.Atoi(s[1:])
+	if err != nil || rank < 1 || rank > size {
+		return Coord{}, fmt.Errorf("invalid move %q", s)
+	}
+	return New(int(s[0]-'a'), rank-1), nil
+}
@@ -0,0 +1,22 @@
+package coord
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for _, size := range []int{6, 8, 10} {
+		for x := 0; x < size; x++ {
+			for y := 0; y < size; y++ {
+				c := New(x, y)
+				if got, err := Parse(c.String(), size); err != nil || got != c {
+					t.Errorf("Parse(%q, %d) = %v, %v", c, size, got, err)
+				}
+			}
+		}
+	}
+
+	for _, s := range []string{"", "d", "3d", "D3", "i1", "a0", "a9", "a10", "d3x", "d+3", "d 3", "d03"} {
+		if got, err := Parse(s, 8); err == nil {
+			t.Errorf("Parse(%q, 8) = %v, want an error", s, got)
+		}
+	}
+}
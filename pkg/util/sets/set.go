@@ -28,3 +28,17 @@ func (set *Set[T]) Contains(element T) bool {
 func (set *Set[T]) IsEmpty() bool {
 	return len(set.m) == 0
 }
+
+func (set *Set[T]) Len() int {
+	return len(set.m)
+}
+
+func (set *Set[T]) Clone() Set[T] {
+	res := Set[T]{
+		m: make(map[T]struct{}, len(set.m)),
+	}
+	for key := range set.m {
+		res.m[key] = struct{}{}
+	}
+	return res
+}
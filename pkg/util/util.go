@@ -1,7 +1,6 @@
 package util
 
 import (
-	"fmt"
 	"log"
 	"math"
 	"sort"
@@ -9,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/ArminGh02/othello-bot/pkg/database"
+	"github.com/ArminGh02/othello-bot/pkg/i18n"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -111,6 +111,14 @@ func (s *Scoreboard) UpdateRankOf(userID int64, winsDelta, lossesDelta int) {
 	}
 }
 
+// SetHidden hides the name of the player from the scoreboards of others.
+func (s *Scoreboard) SetHidden(userID int64, hidden bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scoreboard[s.indexOf(userID)].HiddenFromScoreboard = hidden
+}
+
 func (s *Scoreboard) indexOf(userID int64) int {
 	for i := range s.scoreboard {
 		if s.scoreboard[i].UserID == userID {
@@ -142,7 +150,7 @@ func (s *Scoreboard) RankOf(userID int64) int {
 	panic("")
 }
 
-func (s *Scoreboard) String(userID int64) string {
+func (s *Scoreboard) String(userID int64, lang i18n.Language) string {
 	var sb strings.Builder
 	rank := 0
 	lastScore := math.MinInt
@@ -155,9 +163,9 @@ loop:
 		}
 		switch rank {
 		case 1, 2, 3:
-			sb.WriteString(fmt.Sprintf(
+			sb.WriteString(lang.T(
 				"%d. %s %s Score: %d\n",
-				rank, s.scoreboard[i].Name, emojis[rank], s.scoreboard[i].Score()))
+				rank, nameOn(&s.scoreboard[i], userID, lang), emojis[rank], s.scoreboard[i].Score()))
 		default:
 			break loop
 		}
@@ -182,9 +190,9 @@ loop:
 	}
 	lastScore = s.scoreboard[index-1].Score()
 	for i := index - 1; ; i++ {
-		sb.WriteString(fmt.Sprintf(
+		sb.WriteString(lang.T(
 			"%d. %s Score: %d\n",
-			rank, s.scoreboard[i].Name, s.scoreboard[i].Score()))
+			rank, nameOn(&s.scoreboard[i], userID, lang), s.scoreboard[i].Score()))
 		if i >= to {
 			break
 		}
@@ -197,3 +205,12 @@ loop:
 	sb.WriteString("...\n")
 	return sb.String()
 }
+
+// nameOn returns the name of the player on the scoreboard shown to the user,
+// which is hidden if the player chose so and isn't the user.
+func nameOn(player *database.PlayerDoc, userID int64, lang i18n.Language) string {
+	if player.HiddenFromScoreboard && player.UserID != userID {
+		return lang.T("🕶 Hidden player")
+	}
+	return player.Name
+}
@@ -0,0 +1,9 @@
+// Package resources embeds the images the bot draws boards with and the
+// book of openings, so that it doesn't depend on the working directory
+// it is started from.
+package resources
+
+import "embed"
+
+//go:embed *.png *.txt
+var FS embed.FS